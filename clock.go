@@ -0,0 +1,28 @@
+package prototokens
+
+import "time"
+
+// Clock provides the current time to token creation and validation
+// tests can provide a fake [Clock] to make expiry deterministic and historical tokens can be replayed with a fixed [Clock]
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc allows a plain func to be used as a [Clock]
+type ClockFunc func() time.Time
+
+// Now returns the result of calling the func
+func (cf ClockFunc) Now() time.Time {
+	return cf()
+}
+
+// SystemClock is the default [Clock] backed by [time.Now]
+var SystemClock Clock = ClockFunc(time.Now)
+
+// now returns the current time in UTC from the clock or the [SystemClock] if nil
+func now(c Clock) time.Time {
+	if c == nil {
+		c = SystemClock
+	}
+	return c.Now().UTC()
+}
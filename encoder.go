@@ -0,0 +1,107 @@
+package prototokens
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Encoder turns the marshaled bytes of a [tokenpb.SignedToken] into a string and back
+// [base64.Encoding] and [base32.Encoding] already satisfy this interface
+type Encoder interface {
+	EncodeToString([]byte) string
+	DecodeString(string) ([]byte, error)
+}
+
+var (
+	// Base64URLEncoder encodes with unpadded url-safe base64. this is the default [Encoder]
+	Base64URLEncoder Encoder = base64.RawURLEncoding
+	// Base64StdEncoder encodes with padded standard base64
+	Base64StdEncoder Encoder = base64.StdEncoding
+	// Base32CrockfordEncoder encodes with unpadded Crockford base32
+	// decoding is case-insensitive, maps I and L to 1 and O to 0 and ignores hyphens
+	Base32CrockfordEncoder Encoder = crockfordEncoder{}
+	// Base58Encoder encodes with the bitcoin base58 alphabet
+	Base58Encoder Encoder = radixEncoder{name: "base58", alphabet: base58Alphabet}
+	// Base62Encoder encodes with only ascii letters and digits
+	Base62Encoder Encoder = radixEncoder{name: "base62", alphabet: base62Alphabet}
+)
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var crockfordEncoding = base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding)
+
+// crockfordNormalizer applies the Crockford decoding rules for ambiguous characters
+var crockfordNormalizer = strings.NewReplacer("-", "", "I", "1", "L", "1", "O", "0")
+
+type crockfordEncoder struct{}
+
+func (crockfordEncoder) EncodeToString(b []byte) string {
+	return crockfordEncoding.EncodeToString(b)
+}
+
+func (crockfordEncoder) DecodeString(s string) ([]byte, error) {
+	return crockfordEncoding.DecodeString(crockfordNormalizer.Replace(strings.ToUpper(s)))
+}
+
+// MaxRadixEncodedSize is the maximum length of input [Base58Encoder] and [Base62Encoder] will decode
+// radix decoding is quadratic in the length of the input so these encoders refuse longer input with [ErrTooLarge]
+// no matter what maximum size the manager allows
+const MaxRadixEncodedSize = 4 * 1024
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// radixEncoder treats the input as a big-endian number and encodes it with the alphabet
+// leading zero bytes are preserved as leading copies of the first character of the alphabet
+type radixEncoder struct {
+	name     string
+	alphabet string
+}
+
+func (re radixEncoder) EncodeToString(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(re.alphabet)))
+	n := new(big.Int).SetBytes(b[zeros:])
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, re.alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, re.alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func (re radixEncoder) DecodeString(s string) ([]byte, error) {
+	if len(s) > MaxRadixEncodedSize {
+		return nil, fmt.Errorf("%w: %s input of %d bytes exceeds %d", ErrTooLarge, re.name, len(s), MaxRadixEncodedSize)
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == re.alphabet[0] {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(re.alphabet)))
+	n := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		idx := strings.IndexByte(re.alphabet, s[i])
+		if idx < 0 {
+			return nil, fmt.Errorf("illegal %s data at input byte %d", re.name, i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,92 @@
+package prototokens
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoders(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		{0},
+		{0, 0, 1, 2, 3},
+		[]byte("Hello World!"),
+		{0xff, 0xfe, 0x00, 0x10, 0x80},
+	}
+	for name, enc := range map[string]Encoder{
+		"base64url":       Base64URLEncoder,
+		"base64std":       Base64StdEncoder,
+		"base32crockford": Base32CrockfordEncoder,
+		"base58":          Base58Encoder,
+		"base62":          Base62Encoder,
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, in := range inputs {
+				out, err := enc.DecodeString(enc.EncodeToString(in))
+				require.NoError(t, err)
+				require.Equal(t, in, append([]byte{}, out...), "should roundtrip")
+			}
+		})
+	}
+}
+
+func TestBase58(t *testing.T) {
+	require.Equal(t, "2NEpo7TZRRrLZSi2U", Base58Encoder.EncodeToString([]byte("Hello World!")))
+	require.Equal(t, "11", Base58Encoder.EncodeToString([]byte{0, 0}), "leading zeros should be preserved")
+	_, err := Base58Encoder.DecodeString("0OIl")
+	require.Error(t, err, "ambiguous characters are not in the alphabet")
+}
+
+func TestRadixMaxSize(t *testing.T) {
+	for _, enc := range []Encoder{Base58Encoder, Base62Encoder} {
+		_, err := enc.DecodeString(strings.Repeat("z", MaxRadixEncodedSize))
+		require.NoError(t, err)
+		_, err = enc.DecodeString(strings.Repeat("z", MaxRadixEncodedSize+1))
+		require.ErrorIs(t, err, ErrTooLarge, "should refuse input before decoding it")
+	}
+
+	// the cap applies even when the manager allows larger tokens
+	mc, err := NewManagerConfig(WithEncoder(Base62Encoder))
+	require.NoError(t, err)
+	_, err = mc.Decode(strings.Repeat("z", MaxRadixEncodedSize+1))
+	require.ErrorIs(t, err, ErrDecode)
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	pe, err := NewPrefixedEncoder("myco_live", Base62Encoder)
+	require.NoError(t, err)
+	_, err = pe.DecodeString(pe.EncodeToString(bytes.Repeat([]byte{0xff}, MaxRadixEncodedSize)))
+	require.ErrorIs(t, err, ErrTooLarge, "should cap the payload of a prefixed encoder")
+}
+
+func TestBase32Crockford(t *testing.T) {
+	enc := Base32CrockfordEncoder.EncodeToString([]byte("Hello World!"))
+	require.Equal(t, "91JPRV3F41BPYWKCCGGG", enc)
+	dec, err := Base32CrockfordEncoder.DecodeString("91jprv3f-41bpywkc-cggg")
+	require.NoError(t, err, "decoding should ignore case and hyphens")
+	require.Equal(t, []byte("Hello World!"), dec)
+	dec, err = Base32CrockfordEncoder.DecodeString("o1")
+	require.NoError(t, err)
+	other, err := Base32CrockfordEncoder.DecodeString("01")
+	require.NoError(t, err)
+	require.Equal(t, other, dec, "O should decode as 0")
+	_, err = Base32CrockfordEncoder.DecodeString("U")
+	require.Error(t, err, "U is not in the alphabet")
+}
+
+func TestWithEncoder(t *testing.T) {
+	mc, err := NewManagerConfig()
+	require.NoError(t, err)
+	require.Equal(t, Base64URLEncoder, mc.Encoder, "should default to base64url")
+
+	mc, err = NewManagerConfig(WithEncoder(Base58Encoder))
+	require.NoError(t, err)
+	require.Equal(t, Base58Encoder, mc.Encoder)
+
+	_, err = NewManagerConfig(WithEncoder(nil))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithEncoder(Base58Encoder), WithEncoder(Base64StdEncoder))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
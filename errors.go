@@ -11,10 +11,22 @@ var (
 	ErrNotValid = fmt.Errorf("token is invalid")
 	// ErrNotValidForUsage is the error when a token is not valid for a specific usage
 	ErrNotValidForUsage = fmt.Errorf("token is not valid for provided usages")
+	// ErrClaimMismatch is the error when a token's issuer, audience or subject doesn't match what the verifier requires
+	ErrClaimMismatch = fmt.Errorf("token claim does not match")
+	// ErrNotValidForScopes is the error when a token's scopes don't satisfy the required scopes
+	ErrNotValidForScopes = fmt.Errorf("token is not valid for provided scopes")
+	// ErrRequirement is the error when a token does not meet a [Requirement]
+	ErrRequirement = fmt.Errorf("token does not meet requirement")
 	// ErrNotYetValid is the error when a token is not yet valid
 	ErrNotYetValid = fmt.Errorf("token is not yet valid")
 	// ErrNoLongerValid is the error when a token is not yet valid
 	ErrNoLongerValid = fmt.Errorf("token is no longer valid")
+	// ErrMaxAge is the error when a token was issued longer ago than the verifier's maximum age
+	ErrMaxAge = fmt.Errorf("token is older than the maximum age")
+	// ErrNoVendorMessage is the error when a token has no vendor message
+	ErrNoVendorMessage = fmt.Errorf("token has no vendor message")
+	// ErrVendorMessageType is the error when a token's vendor message is not the requested type
+	ErrVendorMessageType = fmt.Errorf("vendor message is not the requested type")
 	// ErrSign is the error when there is an issue signing a token
 	ErrSign = fmt.Errorf("unable to sign token")
 	// ErrInvalidSignature is the error when the signature is invalid
@@ -25,12 +37,26 @@ var (
 	ErrEncode = fmt.Errorf("unable to encode signed token")
 	// ErrDecode is the error when there is an issue decoding a signed token
 	ErrDecode = fmt.Errorf("unable to decode signed token")
+	// ErrTooLarge is the error when an encoded token is larger than the configured maximum size
+	ErrTooLarge = fmt.Errorf("encoded token is too large")
+	// ErrPrefix is the error when an encoded token does not start with the expected prefix or the prefix itself is invalid
+	ErrPrefix = fmt.Errorf("token prefix is malformed")
+	// ErrChecksum is the error when the checksum of an encoded token does not match indicating a typo or truncation
+	ErrChecksum = fmt.Errorf("token checksum mismatch")
 	// ErrKeyData is the error when the private key data is invalid in some way
 	ErrKeyData = fmt.Errorf("key data is invalid")
+	// ErrVerifyOnly is the error when a [TokenManager] without signing key material is asked to sign a token
+	ErrVerifyOnly = fmt.Errorf("token manager can only verify tokens")
 	// ErrOverwrite is the error when you attempt to overwrite a a token's properties after they've been set
 	ErrOverwrite = fmt.Errorf("attempted overwrite of field")
 	// ErrUnimplemented is the error when a [prototokens.TokenManager] has yet to implement the interface fully
 	ErrUnimplemented = fmt.Errorf("functionality not yet implemented")
 	// ErrTokenRevoked is the error when a [tokenpb.ProtoToken] has been revoked
 	ErrTokenRevoked = fmt.Errorf("token has been revoked")
+	// ErrNoRevocationID is the error when a token without an id is revoked individually
+	ErrNoRevocationID = fmt.Errorf("token has no id to revoke")
+	// ErrRevocationCheck is the error when a [RevocationStorer] is unable to check if a token has been revoked
+	ErrRevocationCheck = fmt.Errorf("unable to check token revocation")
+	// ErrStaleRevocationList is the error when a [tokenpb.RevocationList] is older than the current list or has expired
+	ErrStaleRevocationList = fmt.Errorf("revocation list is stale")
 )
@@ -68,6 +68,14 @@ func main() {
 	if err := manager.ValidFor(context.TODO(), decoded, tokenpb.TokenUsages_TOKEN_USAGES_ROTATION); err != nil {
 		fmt.Println(err.Error())
 	}
+
+	// or do all of the above in a single pass straight from the encoded string
+	// requirements such as prototokens.RequireUsages can be passed as well
+	pt, err := manager.ValidateAndParse(context.TODO(), encoded)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("parsed token: %+v\n", pt)
 }
 
 func keyfunc(_ context.Context) []byte {
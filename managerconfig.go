@@ -0,0 +1,352 @@
+package prototokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxEncodedSize is the default maximum length of an encoded token accepted by Decode
+const DefaultMaxEncodedSize = 16 * 1024
+
+// ManagerOpt is an option for configuring behavior shared across [TokenManager] implementations
+type ManagerOpt func(*ManagerConfig) error
+
+// ManagerConfig holds configuration shared across [TokenManager] implementations
+// Implementations should create it via [NewManagerConfig]
+type ManagerConfig struct {
+	// RevocationStorer is the optional [RevocationStorer] used to revoke and check tokens
+	RevocationStorer RevocationStorer
+	// Encoder is the [Encoder] used to turn signed tokens into strings. defaults to [Base64URLEncoder]
+	Encoder Encoder
+	// Clock is the [Clock] used when validating timestamps. defaults to [SystemClock]
+	Clock Clock
+	// Leeway is the clock skew allowed on both ends of a token's validity window
+	Leeway time.Duration
+	// MaxEncodedSize is the maximum length of an encoded token accepted by Decode. defaults to [DefaultMaxEncodedSize]
+	MaxEncodedSize int
+	// MaxAge is the maximum time since a token was issued regardless of its NotValidAfter if set
+	MaxAge time.Duration
+	// Issuer is the issuer every token must have if set
+	Issuer string
+	// Audience is the audience every token must include if set
+	Audience string
+}
+
+// NewManagerConfig returns a new [ManagerConfig] with the provided options applied
+func NewManagerConfig(opts ...ManagerOpt) (*ManagerConfig, error) {
+	mc := &ManagerConfig{}
+	for _, opt := range opts {
+		if err := opt(mc); err != nil {
+			return nil, err
+		}
+	}
+	if mc.Encoder == nil {
+		mc.Encoder = Base64URLEncoder
+	}
+	if mc.Clock == nil {
+		mc.Clock = SystemClock
+	}
+	if mc.MaxEncodedSize == 0 {
+		mc.MaxEncodedSize = DefaultMaxEncodedSize
+	}
+	return mc, nil
+}
+
+// WithRevocationStorer sets the [RevocationStorer] a [TokenManager] uses for revocation
+func WithRevocationStorer(rs RevocationStorer) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if rs == nil {
+			return fmt.Errorf("revocation storer cannot be nil")
+		}
+		if mc.RevocationStorer != nil {
+			return fmt.Errorf("%w: revocation storer", ErrOverwrite)
+		}
+		mc.RevocationStorer = rs
+		return nil
+	}
+}
+
+// WithEncoder sets the [Encoder] a [TokenManager] uses for Encode and Decode
+func WithEncoder(e Encoder) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if e == nil {
+			return fmt.Errorf("encoder cannot be nil")
+		}
+		if mc.Encoder != nil {
+			return fmt.Errorf("%w: encoder", ErrOverwrite)
+		}
+		mc.Encoder = e
+		return nil
+	}
+}
+
+// WithClock sets the [Clock] a [TokenManager] uses when checking if a token is valid
+func WithClock(c Clock) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if c == nil {
+			return fmt.Errorf("clock cannot be nil")
+		}
+		if mc.Clock != nil {
+			return fmt.Errorf("%w: clock", ErrOverwrite)
+		}
+		mc.Clock = c
+		return nil
+	}
+}
+
+// WithLeeway allows tokens to be used up to the provided duration before they are valid and after they expire
+// to account for clock skew between the host that minted the token and the host validating it
+func WithLeeway(d time.Duration) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if d < 0 {
+			return fmt.Errorf("leeway cannot be negative")
+		}
+		if mc.Leeway != 0 {
+			return fmt.Errorf("%w: leeway", ErrOverwrite)
+		}
+		mc.Leeway = d
+		return nil
+	}
+}
+
+// WithMaxEncodedSize sets the maximum length of an encoded token a [TokenManager] will decode
+// larger input is rejected before it is decoded so unauthenticated input can't allocate unboundedly
+func WithMaxEncodedSize(n int) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("max encoded size must be positive")
+		}
+		if mc.MaxEncodedSize != 0 {
+			return fmt.Errorf("%w: max encoded size", ErrOverwrite)
+		}
+		mc.MaxEncodedSize = n
+		return nil
+	}
+}
+
+// WithMaxAge rejects tokens issued longer ago than the provided duration with [ErrMaxAge]
+// even if they haven't reached their NotValidAfter. the age is measured from [IssuedAt]
+func WithMaxAge(d time.Duration) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if d <= 0 {
+			return fmt.Errorf("max age must be positive")
+		}
+		if mc.MaxAge != 0 {
+			return fmt.Errorf("%w: max age", ErrOverwrite)
+		}
+		mc.MaxAge = d
+		return nil
+	}
+}
+
+// WithRequiredIssuer makes a [TokenManager] reject tokens not minted by the provided issuer with [ErrClaimMismatch]
+func WithRequiredIssuer(issuer string) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if issuer == "" {
+			return fmt.Errorf("issuer cannot be empty")
+		}
+		if mc.Issuer != "" {
+			return fmt.Errorf("%w: issuer", ErrOverwrite)
+		}
+		mc.Issuer = issuer
+		return nil
+	}
+}
+
+// WithRequiredAudience makes a [TokenManager] reject tokens that don't include the provided audience with [ErrClaimMismatch]
+func WithRequiredAudience(audience string) ManagerOpt {
+	return func(mc *ManagerConfig) error {
+		if audience == "" {
+			return fmt.Errorf("audience cannot be empty")
+		}
+		if mc.Audience != "" {
+			return fmt.Errorf("%w: audience", ErrOverwrite)
+		}
+		mc.Audience = audience
+		return nil
+	}
+}
+
+// ValidateToken validates a [tokenpb.ProtoToken] whose signature has already been verified
+// requested are the usages the caller is validating the token for if any
+// the checks are done in layers based on how expensive they are
+// - reject revocation lists unless [tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST] was requested.
+// lists are signed with the same key as every other token and published so they must never work as bearer tokens
+// - check timestamps and the maximum age in the token allowing for the configured leeway
+// - check the required issuer and audience if configured
+// - check the revocation store last since it's likely a network call
+// failures are returned as a [ValidationError] including the token's id and sid
+func (mc *ManagerConfig) ValidateToken(ctx context.Context, tok *tokenpb.ProtoToken, requested ...tokenpb.TokenUsages) error {
+	if hasUsage(tok, tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST) && !containsUsage(requested, tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST) {
+		return tokenError(tok, ReasonUsage, 0, fmt.Errorf("%w: revocation lists are not valid as tokens", ErrNotValidForUsage))
+	}
+
+	current := mc.Now()
+	var leeway time.Duration
+	if mc != nil {
+		leeway = mc.Leeway
+	}
+	nvb := tok.GetTimestamps().GetNotValidBefore().AsTime().UTC()
+	if current.Before(nvb.Add(-leeway)) {
+		delta := nvb.Sub(current)
+		return tokenError(tok, ReasonNotYetValid, delta, fmt.Errorf("%w: valid in %s (leeway %s)", ErrNotYetValid, delta, leeway))
+	}
+
+	nva := tok.GetTimestamps().GetNotValidAfter().AsTime().UTC()
+	if current.After(nva.Add(leeway)) {
+		delta := current.Sub(nva)
+		return tokenError(tok, ReasonExpired, delta, fmt.Errorf("%w: expired %s ago (leeway %s)", ErrNoLongerValid, delta, leeway))
+	}
+
+	if mc != nil && mc.MaxAge > 0 {
+		age := current.Sub(IssuedAt(tok))
+		if age > mc.MaxAge+leeway {
+			delta := age - mc.MaxAge
+			return tokenError(tok, ReasonExpired, delta, fmt.Errorf("%w: %w: issued %s ago (max age %s, leeway %s)", ErrNoLongerValid, ErrMaxAge, age, mc.MaxAge, leeway))
+		}
+	}
+
+	if mc != nil && mc.Issuer != "" {
+		if err := RequireIssuer(mc.Issuer)(tok); err != nil {
+			return err
+		}
+	}
+	if mc != nil && mc.Audience != "" {
+		if err := RequireAudience(mc.Audience)(tok); err != nil {
+			return err
+		}
+	}
+
+	if err := mc.CheckRevocation(ctx, tok); err != nil {
+		reason := ReasonRevocationCheck
+		if errors.Is(err, ErrTokenRevoked) {
+			reason = ReasonRevoked
+		}
+		return tokenError(tok, reason, 0, err)
+	}
+	return nil
+}
+
+// Revoke revokes the token by its [RevocationID] in the configured [RevocationStorer]
+// tokens without an id return [ErrNoRevocationID] and can only be revoked in bulk via a [BulkRevocationStorer]
+// if the store is an [ExpiringRevocationStorer], the revocation is kept until the token could no longer pass
+// [ManagerConfig.ValidateToken] anyway: its NotValidAfter plus the leeway, or the maximum age plus the leeway if that is sooner
+// returns [ErrUnimplemented] if no [RevocationStorer] is configured
+func (mc *ManagerConfig) Revoke(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	if mc == nil || mc.RevocationStorer == nil {
+		return fmt.Errorf("%w: no revocation storer configured", ErrUnimplemented)
+	}
+	id, err := RevocationID(pt)
+	if err != nil {
+		return err
+	}
+	if ers, ok := mc.RevocationStorer.(ExpiringRevocationStorer); ok && pt.GetTimestamps().GetNotValidAfter() != nil {
+		return ers.RevokeUntil(ctx, id, mc.validUntil(pt))
+	}
+	return mc.RevocationStorer.Revoke(ctx, id)
+}
+
+// validUntil returns the last time the token can pass [ManagerConfig.ValidateToken]
+func (mc *ManagerConfig) validUntil(pt *tokenpb.ProtoToken) time.Time {
+	until := pt.GetTimestamps().GetNotValidAfter().AsTime().UTC().Add(mc.Leeway)
+	if mc.MaxAge > 0 {
+		if maxAge := IssuedAt(pt).Add(mc.MaxAge + mc.Leeway); maxAge.Before(until) {
+			until = maxAge
+		}
+	}
+	return until
+}
+
+// CheckRevocation checks the configured [RevocationStorer] to see if the token has been revoked
+// tokens without an id can't have been revoked individually so only bulk revocation applies to them
+// if the store is a [BulkRevocationStorer], the token's sid and [IssuedAt] are checked as well
+// if no [RevocationStorer] is configured, no check is performed
+// errors from the store other than [ErrTokenRevoked] are returned as [ErrRevocationCheck]
+// so that a failing store never results in a revoked token being considered valid
+func (mc *ManagerConfig) CheckRevocation(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	if mc == nil || mc.RevocationStorer == nil {
+		return nil
+	}
+	if id, err := RevocationID(pt); err == nil {
+		if err := mc.RevocationStorer.CheckRevocation(ctx, id); err != nil {
+			return revocationCheckError(err)
+		}
+	}
+	if brs, ok := mc.RevocationStorer.(BulkRevocationStorer); ok {
+		if err := brs.CheckBulkRevocation(ctx, pt.GetSid(), IssuedAt(pt)); err != nil {
+			return revocationCheckError(err)
+		}
+	}
+	return nil
+}
+
+// Encode marshals a signed token and encodes it with the configured [Encoder]
+func (mc *ManagerConfig) Encode(st *tokenpb.SignedToken) (string, error) {
+	b, err := proto.Marshal(st)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrMarshal, err)
+	}
+	return mc.encoder().EncodeToString(b), nil
+}
+
+// Decode decodes a string with the configured [Encoder] and unmarshals the signed token
+// input longer than the configured maximum returns [ErrTooLarge] without being decoded
+// failures are returned as a [ValidationError] with [ReasonMalformed]
+func (mc *ManagerConfig) Decode(s string) (*tokenpb.SignedToken, error) {
+	if limit := mc.maxEncodedSize(); len(s) > limit {
+		return nil, NewValidationError(ReasonMalformed, fmt.Errorf("%w: %w: %d bytes exceeds %d", ErrDecode, ErrTooLarge, len(s), limit))
+	}
+	b, err := mc.encoder().DecodeString(s)
+	if err != nil {
+		return nil, NewValidationError(ReasonMalformed, fmt.Errorf("%w: %w", ErrDecode, err))
+	}
+	st := &tokenpb.SignedToken{}
+	if err := proto.Unmarshal(b, st); err != nil {
+		return nil, NewValidationError(ReasonMalformed, fmt.Errorf("%w: %w", ErrUnmarshal, err))
+	}
+	return st, nil
+}
+
+// Now returns the current time in UTC from the configured [Clock]
+func (mc *ManagerConfig) Now() time.Time {
+	if mc == nil {
+		return now(SystemClock)
+	}
+	return now(mc.Clock)
+}
+
+func (mc *ManagerConfig) maxEncodedSize() int {
+	if mc == nil || mc.MaxEncodedSize == 0 {
+		return DefaultMaxEncodedSize
+	}
+	return mc.MaxEncodedSize
+}
+
+func (mc *ManagerConfig) encoder() Encoder {
+	if mc == nil || mc.Encoder == nil {
+		return Base64URLEncoder
+	}
+	return mc.Encoder
+}
+
+// tokenError returns a [ValidationError] for a token whose signature has already been verified
+func tokenError(tok *tokenpb.ProtoToken, reason ValidationReason, delta time.Duration, cause error) *ValidationError {
+	ve := NewValidationError(reason, cause)
+	ve.ID = tok.GetId()
+	ve.SID = tok.GetSid()
+	ve.Delta = delta
+	return ve
+}
+
+func revocationCheckError(err error) error {
+	if errors.Is(err, ErrTokenRevoked) {
+		return ErrTokenRevoked
+	}
+	return fmt.Errorf("%w: %w", ErrRevocationCheck, err)
+}
@@ -0,0 +1,209 @@
+package prototokens
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+	"github.com/lusis/prototokens/prototokenstest"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(start)
+	mc, err := NewManagerConfig(WithClock(fc))
+	require.NoError(t, err)
+	tok, err := New(time.Hour, WithTokenClock(fc))
+	require.NoError(t, err)
+
+	require.NoError(t, mc.ValidateToken(context.Background(), tok))
+	fc.Advance(time.Hour)
+	require.NoError(t, mc.ValidateToken(context.Background(), tok), "should be valid up to and including not valid after")
+	fc.Advance(time.Nanosecond)
+	require.ErrorIs(t, mc.ValidateToken(context.Background(), tok), ErrNoLongerValid)
+	fc.Set(start.Add(-time.Nanosecond))
+	require.ErrorIs(t, mc.ValidateToken(context.Background(), tok), ErrNotYetValid)
+
+	_, err = NewManagerConfig(WithClock(nil))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithClock(fc), WithClock(SystemClock))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
+
+func TestLeeway(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(start)
+	mc, err := NewManagerConfig(WithClock(fc), WithLeeway(time.Second))
+	require.NoError(t, err)
+	tok, err := New(time.Hour, WithTokenClock(fc))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	fc.Set(start.Add(-500 * time.Millisecond))
+	require.NoError(t, mc.ValidateToken(ctx, tok), "should allow skew before not valid before")
+	fc.Set(start.Add(-2 * time.Second))
+	err = mc.ValidateToken(ctx, tok)
+	require.ErrorIs(t, err, ErrNotYetValid)
+	require.ErrorContains(t, err, "valid in 2s")
+
+	fc.Set(start.Add(time.Hour + 500*time.Millisecond))
+	require.NoError(t, mc.ValidateToken(ctx, tok), "should allow skew after not valid after")
+	fc.Set(start.Add(time.Hour + 3*time.Second))
+	err = mc.ValidateToken(ctx, tok)
+	require.ErrorIs(t, err, ErrNoLongerValid)
+	require.ErrorContains(t, err, "expired 3s ago")
+
+	_, err = NewManagerConfig(WithLeeway(-time.Second))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithLeeway(time.Second), WithLeeway(time.Minute))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
+
+func TestMaxEncodedSize(t *testing.T) {
+	mc, err := NewManagerConfig(WithMaxEncodedSize(64))
+	require.NoError(t, err)
+	tok, err := New(time.Hour)
+	require.NoError(t, err)
+	b, err := proto.Marshal(tok)
+	require.NoError(t, err)
+
+	small, err := mc.Encode(&tokenpb.SignedToken{Prototoken: b[:10]})
+	require.NoError(t, err)
+	_, err = mc.Decode(small)
+	require.NoError(t, err)
+
+	large, err := mc.Encode(&tokenpb.SignedToken{Prototoken: b, Signature: make([]byte, 64)})
+	require.NoError(t, err)
+	_, err = mc.Decode(large)
+	require.ErrorIs(t, err, ErrDecode)
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	def, err := NewManagerConfig()
+	require.NoError(t, err)
+	require.Equal(t, DefaultMaxEncodedSize, def.MaxEncodedSize)
+	_, err = def.Decode(strings.Repeat("A", DefaultMaxEncodedSize+1))
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = NewManagerConfig(WithMaxEncodedSize(0))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithMaxEncodedSize(1), WithMaxEncodedSize(2))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
+
+func TestRequiredClaims(t *testing.T) {
+	mc, err := NewManagerConfig(WithRequiredIssuer("billing"), WithRequiredAudience("admin"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		opts []TokenOpt
+		err  bool
+	}{
+		"match":          {opts: []TokenOpt{WithIssuer("billing"), WithAudience("billing", "admin")}},
+		"wrong-issuer":   {opts: []TokenOpt{WithIssuer("support"), WithAudience("admin")}, err: true},
+		"no-issuer":      {opts: []TokenOpt{WithAudience("admin")}, err: true},
+		"wrong-audience": {opts: []TokenOpt{WithIssuer("billing"), WithAudience("billing")}, err: true},
+		"no-audience":    {opts: []TokenOpt{WithIssuer("billing")}, err: true},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			tok, err := New(time.Hour, tc.opts...)
+			require.NoError(t, err)
+			err = mc.ValidateToken(ctx, tok)
+			if !tc.err {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrClaimMismatch)
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			require.Equal(t, ReasonClaim, ve.Reason)
+		})
+	}
+
+	_, err = NewManagerConfig(WithRequiredIssuer(""))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithRequiredAudience(""))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithRequiredIssuer("a"), WithRequiredIssuer("b"))
+	require.ErrorIs(t, err, ErrOverwrite)
+	_, err = NewManagerConfig(WithRequiredAudience("a"), WithRequiredAudience("b"))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
+
+func TestMaxAge(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(start)
+	mc, err := NewManagerConfig(WithClock(fc), WithMaxAge(30*24*time.Hour))
+	require.NoError(t, err)
+	tok, err := New(365*24*time.Hour, WithTokenClock(fc))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	fc.Advance(29 * 24 * time.Hour)
+	require.NoError(t, mc.ValidateToken(ctx, tok))
+	fc.Advance(2 * 24 * time.Hour)
+	err = mc.ValidateToken(ctx, tok)
+	require.ErrorIs(t, err, ErrMaxAge)
+	require.ErrorIs(t, err, ErrNoLongerValid)
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, ReasonExpired, ve.Reason)
+	require.Equal(t, 24*time.Hour, ve.Delta)
+
+	// age is measured from issued_at not from when the token became valid
+	fc.Set(start)
+	delayed, err := New(365*24*time.Hour, WithTokenClock(fc), WithNotValidBefore(start.Add(20*24*time.Hour)))
+	require.NoError(t, err)
+	fc.Advance(31 * 24 * time.Hour)
+	require.ErrorIs(t, mc.ValidateToken(ctx, delayed), ErrMaxAge)
+
+	_, err = NewManagerConfig(WithMaxAge(0))
+	require.Error(t, err)
+	_, err = NewManagerConfig(WithMaxAge(time.Hour), WithMaxAge(time.Minute))
+	require.ErrorIs(t, err, ErrOverwrite)
+}
+
+// untilStorer records how long a revocation is kept for
+type untilStorer struct {
+	UnimplementedRevocationStorer
+	until time.Time
+}
+
+func (us *untilStorer) RevokeUntil(_ context.Context, _ string, until time.Time) error {
+	us.until = until
+	return nil
+}
+
+func TestRevokeUntil(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(start)
+	ctx := context.Background()
+	tok, err := New(time.Hour, WithTokenClock(fc))
+	require.NoError(t, err)
+
+	us := &untilStorer{}
+	mc, err := NewManagerConfig(WithClock(fc), WithLeeway(time.Minute), WithRevocationStorer(us))
+	require.NoError(t, err)
+	require.NoError(t, mc.Revoke(ctx, tok))
+	require.Equal(t, start.Add(time.Hour+time.Minute), us.until, "revocation should outlive the leeway")
+	// the revocation must not be forgotten while the token could still pass validation
+	check, err := NewManagerConfig(WithClock(fc), WithLeeway(time.Minute))
+	require.NoError(t, err)
+	fc.Set(us.until)
+	require.NoError(t, check.ValidateToken(ctx, tok))
+	fc.Advance(time.Nanosecond)
+	require.ErrorIs(t, check.ValidateToken(ctx, tok), ErrNoLongerValid)
+
+	us = &untilStorer{}
+	mc, err = NewManagerConfig(WithClock(fc), WithLeeway(time.Minute), WithMaxAge(10*time.Minute), WithRevocationStorer(us))
+	require.NoError(t, err)
+	require.NoError(t, mc.Revoke(ctx, tok))
+	require.Equal(t, start.Add(11*time.Minute), us.until, "a shorter max age should bound the revocation")
+}
@@ -0,0 +1,7 @@
+// Package aeadurl implements [prototokens.TokenManager] via AES-256-GCM authenticated encryption and url-safe encoded strings
+// unlike the signing managers, the contents of the token can only be read by holders of the key
+//
+// nonces are random 96-bit values so a single key must not encrypt more than about 2^32 tokens.
+// past that point nonce collisions become likely and a collision breaks both confidentiality and authenticity.
+// rotate the key returned by the key func well before then
+package aeadurl
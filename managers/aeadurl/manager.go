@@ -0,0 +1,146 @@
+package aeadurl
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// KeySize is the size of the AES-256 key returned by [KeyDataFunc]
+const KeySize = 32
+
+// additionalData binds ciphertexts to this format so they can't be confused with anything else encrypted with the same key
+var additionalData = []byte("prototokens.v1.ProtoToken")
+
+// Manager is an implementation of [prototokens.TokenManager] that:
+// - encrypts tokens with AES-256-GCM with [KeyDataFunc] returning the key
+// - stores the nonce and ciphertext in the prototoken field of [tokenpb.SignedToken]. there is no separate signature
+// since the authenticated encryption already detects tampering
+// - encodes/decodes with the configured [prototokens.Encoder] (url-safe base64 by default)
+// - checks revocation with an optional [prototokens.RevocationStorer]
+type Manager struct {
+	*prototokens.Validator
+	keyDataFunc KeyDataFunc
+	cfg         *prototokens.ManagerConfig
+}
+
+// KeyDataFunc is a func that can return the AES-256 key
+type KeyDataFunc func(context.Context) []byte
+
+// New returns a new [aeadurl.Manager]
+func New(keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	if keyDataFunc == nil {
+		return nil, fmt.Errorf("%w: key data func cannot be nil", prototokens.ErrKeyData)
+	}
+	m := &Manager{keyDataFunc: keyDataFunc}
+	// get the key and make sure it's valid
+	if _, err := m.aead(context.Background()); err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.cfg = cfg
+	m.Validator = prototokens.NewValidator(cfg, m.open)
+	return m, nil
+}
+
+// Sign encrypts the token
+func (am *Manager) Sign(ctx context.Context, pt *tokenpb.ProtoToken) (*tokenpb.SignedToken, error) {
+	ctx, span := internal.StartSpan(ctx, "Sign")
+	defer span.End()
+	span.AddEvent("marshal start")
+	b, err := proto.Marshal(pt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	span.AddEvent("marshal end")
+
+	sealed, err := am.seal(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrSign, err)
+	}
+	return &tokenpb.SignedToken{Prototoken: sealed}, nil
+}
+
+// RevokeToken revokes a token via the configured [prototokens.RevocationStorer]
+// the revocation id is determined by [prototokens.RevocationID]
+func (am *Manager) RevokeToken(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeToken")
+	defer span.End()
+	return am.cfg.Revoke(ctx, pt)
+}
+
+// Encode encodes an encrypted token with the configured [prototokens.Encoder]
+func (am *Manager) Encode(ctx context.Context, st *tokenpb.SignedToken) (string, error) {
+	_, span := internal.StartSpan(ctx, "Encode")
+	defer span.End()
+	return am.cfg.Encode(st)
+}
+
+// Decode decodes an encrypted token with the configured [prototokens.Encoder]
+func (am *Manager) Decode(ctx context.Context, s string) (*tokenpb.SignedToken, error) {
+	_, span := internal.StartSpan(ctx, "Decode")
+	defer span.End()
+	return am.cfg.Decode(s)
+}
+
+// aead returns the cipher after making sure the key is valid
+func (am *Manager) aead(ctx context.Context) (cipher.AEAD, error) {
+	key := am.keyDataFunc(ctx)
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: invalid key size returned (want: %d have: %d)", prototokens.ErrKeyData, KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrKeyData, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrKeyData, err)
+	}
+	return aead, nil
+}
+
+// seal encrypts the data returning the nonce followed by the ciphertext
+func (am *Manager) seal(ctx context.Context, data []byte) ([]byte, error) {
+	aead, err := am.aead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, additionalData), nil
+}
+
+// open decrypts the token returning the plaintext token bytes
+// nothing is returned until the ciphertext has been authenticated
+func (am *Manager) open(ctx context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	aead, err := am.aead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sealed := st.GetPrototoken()
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, prototokens.NewValidationError(prototokens.ReasonMalformed, fmt.Errorf("%w: ciphertext too short", prototokens.ErrDecode))
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	b, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, prototokens.ErrTamper
+	}
+	return b, nil
+}
@@ -0,0 +1,155 @@
+package aeadurl
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.TokenManager)(nil), &Manager{}, "should implement the interface")
+}
+
+func TestHappyPath(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	enc, err := data.m.Encode(ctx, data.st)
+	require.NoError(t, err, "should encode")
+	dec, err := data.m.Decode(ctx, enc)
+	require.NoError(t, err, "should decode")
+	require.True(t, proto.Equal(data.st, dec), "original and decoded signed token should be the same")
+	require.Empty(t, dec.GetSignature(), "encrypted tokens have no separate signature")
+	require.NotContains(t, string(dec.GetPrototoken()), t.Name()+"_vendor", "token contents should not be readable")
+
+	vt, err := data.m.GetValidatedToken(ctx, dec)
+	require.NoError(t, err, "should not error")
+	require.True(t, proto.Equal(data.pt, vt), "original and validated token should be the same")
+	require.NoError(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+	require.ErrorIs(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE), prototokens.ErrNotValidForUsage)
+
+	pt, err := data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), prototokens.RequireSID(data.pt.GetSid()))
+	require.NoError(t, err, "should validate and parse in one pass")
+	require.True(t, proto.Equal(data.pt, pt), "original and parsed token should be the same")
+	_, err = data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_MACHINE))
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+
+	again, err := data.m.Sign(ctx, data.pt)
+	require.NoError(t, err)
+	require.NotEqual(t, data.st.GetPrototoken(), again.GetPrototoken(), "nonces should be random")
+}
+
+func TestValidation(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("tamper", func(t *testing.T) {
+		st := proto.Clone(data.st).(*tokenpb.SignedToken)
+		st.Prototoken[len(st.Prototoken)-1] ^= 0xff
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrTamper)
+	})
+	t.Run("plaintext", func(t *testing.T) {
+		b, err := proto.Marshal(data.pt)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, &tokenpb.SignedToken{Prototoken: b}), prototokens.ErrTamper)
+	})
+	t.Run("other-key", func(t *testing.T) {
+		other, err := setupTest(t.Name(), nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, other.st), prototokens.ErrTamper)
+	})
+	t.Run("too-short", func(t *testing.T) {
+		require.ErrorIs(t, data.m.Validate(ctx, &tokenpb.SignedToken{Prototoken: []byte("short")}), prototokens.ErrDecode)
+	})
+	t.Run("not-yet-valid", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Timestamps.NotValidBefore = timestamppb.New(time.Now().Add(5 * time.Minute))
+		st, err := data.m.Sign(ctx, cloned)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNotYetValid)
+	})
+	t.Run("no-longer-valid", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Timestamps.NotValidAfter = timestamppb.New(time.Now().Add(-5 * time.Minute))
+		st, err := data.m.Sign(ctx, cloned)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNoLongerValid)
+	})
+}
+
+func TestKeyData(t *testing.T) {
+	_, err := New(nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	_, err = New(func(_ context.Context) []byte { return make([]byte, 16) })
+	require.ErrorIs(t, err, prototokens.ErrKeyData, "only AES-256 keys are allowed")
+
+	// keys are checked on every call
+	key := make([]byte, KeySize)
+	m, err := New(func(_ context.Context) []byte { return key })
+	require.NoError(t, err)
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	st, err := m.Sign(context.Background(), pt)
+	require.NoError(t, err)
+	key = key[:16]
+	_, err = m.Sign(context.Background(), pt)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	require.ErrorIs(t, m.Validate(context.Background(), st), prototokens.ErrKeyData)
+}
+
+type setupData struct {
+	pt *tokenpb.ProtoToken
+	st *tokenpb.SignedToken
+	m  prototokens.TokenManager
+}
+
+func setupTest(testName string, keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*setupData, error) {
+	if keyDataFunc == nil {
+		keydata := make([]byte, KeySize)
+		_, err := io.ReadFull(rand.Reader, keydata)
+		if err != nil {
+			return nil, err
+		}
+		keyDataFunc = func(_ context.Context) []byte {
+			return keydata
+		}
+	}
+
+	m, err := New(keyDataFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := prototokens.New(
+		1*time.Hour,
+		prototokens.WithID(testName+"_id"),
+		prototokens.WithSID(testName+"_sid"),
+		prototokens.WithVendor([]byte(testName+"_vendor")),
+		prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := m.Sign(context.TODO(), pt)
+	if err != nil {
+		return nil, err
+	}
+	return &setupData{
+		pt: pt,
+		st: st,
+		m:  m,
+	}, nil
+}
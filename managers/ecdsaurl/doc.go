@@ -0,0 +1,3 @@
+// Package ecdsaurl implements [prototokens.TokenManager] via ECDSA P-256/SHA-256 signatures and url-safe encoded strings
+// it is intended for environments that require FIPS-approved algorithms
+package ecdsaurl
@@ -0,0 +1,133 @@
+package ecdsaurl
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	"github.com/lusis/prototokens"
+)
+
+// KeyDataFunc is a func that can return a PEM-encoded PKCS#8 P-256 private key
+type KeyDataFunc func(context.Context) []byte
+
+// PublicKeyFunc is a func that can return a PEM-encoded PKIX P-256 public key
+type PublicKeyFunc func(context.Context) []byte
+
+// key is either a private key that can sign and verify or a public key that can only verify
+// the funcs are still called on every use so keys can rotate but PEM is only parsed again when it changes
+type key struct {
+	keyDataFunc   KeyDataFunc
+	publicKeyFunc PublicKeyFunc
+
+	mu sync.Mutex
+	// pem is the key material priv and pub were parsed from
+	pem  []byte
+	priv *ecdsa.PrivateKey
+	pub  *ecdsa.PublicKey
+}
+
+// validate checks that the key material is usable
+func (k *key) validate(ctx context.Context) error {
+	if k.keyDataFunc == nil && k.publicKeyFunc == nil {
+		return fmt.Errorf("%w: no key data provided", prototokens.ErrKeyData)
+	}
+	_, err := k.public(ctx)
+	return err
+}
+
+// private returns the private key or [prototokens.ErrVerifyOnly] for public keys
+func (k *key) private(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	if k.keyDataFunc == nil {
+		return nil, prototokens.ErrVerifyOnly
+	}
+	data := k.keyDataFunc(ctx)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.priv != nil && bytes.Equal(data, k.pem) {
+		return k.priv, nil
+	}
+	priv, err := parsePrivate(data)
+	if err != nil {
+		return nil, err
+	}
+	k.pem, k.priv = bytes.Clone(data), priv
+	return priv, nil
+}
+
+// public returns the public key used to verify signatures
+func (k *key) public(ctx context.Context) (*ecdsa.PublicKey, error) {
+	if k.publicKeyFunc == nil {
+		priv, err := k.private(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &priv.PublicKey, nil
+	}
+	data := k.publicKeyFunc(ctx)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.pub != nil && bytes.Equal(data, k.pem) {
+		return k.pub, nil
+	}
+	pub, err := parsePublic(data)
+	if err != nil {
+		return nil, err
+	}
+	k.pem, k.pub = bytes.Clone(data), pub
+	return pub, nil
+}
+
+func parsePrivate(data []byte) (*ecdsa.PrivateKey, error) {
+	der, err := decodePEM(data, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrKeyData, err)
+	}
+	priv, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an ecdsa private key (%T)", prototokens.ErrKeyData, parsed)
+	}
+	if priv.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: curve must be P-256 (have: %s)", prototokens.ErrKeyData, priv.Curve.Params().Name)
+	}
+	return priv, nil
+}
+
+func parsePublic(data []byte) (*ecdsa.PublicKey, error) {
+	der, err := decodePEM(data, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrKeyData, err)
+	}
+	pub, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an ecdsa public key (%T)", prototokens.ErrKeyData, parsed)
+	}
+	if pub.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: curve must be P-256 (have: %s)", prototokens.ErrKeyData, pub.Curve.Params().Name)
+	}
+	return pub, nil
+}
+
+func decodePEM(data []byte, blockType string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", prototokens.ErrKeyData)
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("%w: unexpected PEM block type (want: %s have: %s)", prototokens.ErrKeyData, blockType, block.Type)
+	}
+	return block.Bytes, nil
+}
@@ -0,0 +1,126 @@
+package ecdsaurl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Manager is an implementation of [prototokens.TokenManager] that:
+// - signs tokens with ECDSA P-256/SHA-256 with [KeyDataFunc] returning a PEM-encoded PKCS#8 private key
+// - encodes/decodes with the configured [prototokens.Encoder] (url-safe base64 by default)
+// - checks revocation with an optional [prototokens.RevocationStorer]
+// - can verify without signing material when created with [NewVerifier]
+type Manager struct {
+	*prototokens.Validator
+	key *key
+	cfg *prototokens.ManagerConfig
+}
+
+// New returns a new [ecdsaurl.Manager]
+func New(keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	return newManager(&key{keyDataFunc: keyDataFunc}, opts...)
+}
+
+// NewVerifier returns a new [ecdsaurl.Manager] that can only verify tokens
+// Sign returns [prototokens.ErrVerifyOnly] while every other method works normally
+func NewVerifier(publicKeyFunc PublicKeyFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	return newManager(&key{publicKeyFunc: publicKeyFunc}, opts...)
+}
+
+func newManager(k *key, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	// get the key and make sure it's valid
+	if err := k.validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{key: k, cfg: cfg}
+	m.Validator = prototokens.NewValidator(cfg, m.open)
+	return m, nil
+}
+
+// Sign signs the token
+func (em *Manager) Sign(ctx context.Context, pt *tokenpb.ProtoToken) (*tokenpb.SignedToken, error) {
+	ctx, span := internal.StartSpan(ctx, "Sign")
+	defer span.End()
+	span.AddEvent("marshal start")
+	b, err := proto.Marshal(pt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	span.AddEvent("marshal end")
+
+	sig, err := em.sign(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrSign, err)
+	}
+	st := &tokenpb.SignedToken{
+		Signature:  sig,
+		Prototoken: b,
+	}
+	return st, nil
+}
+
+// RevokeToken revokes a token via the configured [prototokens.RevocationStorer]
+// the revocation id is determined by [prototokens.RevocationID]
+func (em *Manager) RevokeToken(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeToken")
+	defer span.End()
+	return em.cfg.Revoke(ctx, pt)
+}
+
+// Encode encodes a signed token with the configured [prototokens.Encoder]
+func (em *Manager) Encode(ctx context.Context, st *tokenpb.SignedToken) (string, error) {
+	_, span := internal.StartSpan(ctx, "Encode")
+	defer span.End()
+	return em.cfg.Encode(st)
+}
+
+// Decode decodes a signed token with the configured [prototokens.Encoder]
+func (em *Manager) Decode(ctx context.Context, s string) (*tokenpb.SignedToken, error) {
+	_, span := internal.StartSpan(ctx, "Decode")
+	defer span.End()
+	return em.cfg.Decode(s)
+}
+
+func (em *Manager) sign(ctx context.Context, data []byte) ([]byte, error) {
+	priv, err := em.key.private(ctx)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	return ecdsa.SignASN1(rand.Reader, priv, digest[:])
+}
+
+// open verifies the signature over the raw token bytes returning them once they can be trusted
+func (em *Manager) open(ctx context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	if err := em.verify(ctx, st.GetSignature(), st.GetPrototoken()); err != nil {
+		return nil, err
+	}
+	return st.GetPrototoken(), nil
+}
+
+func (em *Manager) verify(ctx context.Context, sig []byte, data []byte) error {
+	pub, err := em.key.public(ctx)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	if verified := ecdsa.VerifyASN1(pub, digest[:], sig); !verified {
+		return prototokens.ErrTamper
+	}
+	return nil
+}
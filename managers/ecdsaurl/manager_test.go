@@ -0,0 +1,193 @@
+package ecdsaurl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.TokenManager)(nil), &Manager{}, "should implement the interface")
+}
+
+func TestHappyPath(t *testing.T) {
+	data, err := setupTest(t, t.Name())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	enc, err := data.m.Encode(ctx, data.st)
+	require.NoError(t, err, "should encode")
+	dec, err := data.m.Decode(ctx, enc)
+	require.NoError(t, err, "should decode")
+	require.True(t, proto.Equal(data.st, dec), "original and decoded signed token should be the same")
+
+	vt, err := data.m.GetValidatedToken(ctx, dec)
+	require.NoError(t, err, "should not error")
+	require.True(t, proto.Equal(data.pt, vt), "original and validated token should be the same")
+	require.NoError(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+	require.ErrorIs(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE), prototokens.ErrNotValidForUsage)
+
+	pt, err := data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), prototokens.RequireSID(data.pt.GetSid()))
+	require.NoError(t, err, "should validate and parse in one pass")
+	require.True(t, proto.Equal(data.pt, pt), "original and parsed token should be the same")
+	_, err = data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_MACHINE))
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+}
+
+func TestValidation(t *testing.T) {
+	data, err := setupTest(t, t.Name())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("tamper", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Sid = "mismatch"
+		b, err := proto.Marshal(cloned)
+		require.NoError(t, err)
+		st := proto.Clone(data.st).(*tokenpb.SignedToken)
+		st.Prototoken = b
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrTamper)
+	})
+	t.Run("other-key", func(t *testing.T) {
+		other, err := setupTest(t, t.Name())
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, other.st), prototokens.ErrTamper)
+	})
+	t.Run("no-longer-valid", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Timestamps.NotValidAfter = timestamppb.New(time.Now().Add(-5 * time.Minute))
+		st, err := data.m.Sign(ctx, cloned)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNoLongerValid)
+	})
+}
+
+func TestVerifier(t *testing.T) {
+	data, err := setupTest(t, t.Name())
+	require.NoError(t, err)
+	ctx := context.Background()
+	priv, err := data.m.(*Manager).key.private(ctx)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	m, err := NewVerifier(func(_ context.Context) []byte { return pubPEM })
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(ctx, data.st))
+	_, err = m.Sign(ctx, data.pt)
+	require.ErrorIs(t, err, prototokens.ErrVerifyOnly)
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	first, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	second, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keydata := privatePEM(t, first)
+	m, err := New(func(_ context.Context) []byte { return keydata })
+	require.NoError(t, err)
+
+	cached, err := m.key.private(ctx)
+	require.NoError(t, err)
+	priv, err := m.key.private(ctx)
+	require.NoError(t, err)
+	require.Same(t, cached, priv, "should not parse unchanged key data again")
+
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	st, err := m.Sign(ctx, pt)
+	require.NoError(t, err)
+
+	keydata = privatePEM(t, second)
+	require.ErrorIs(t, m.Validate(ctx, st), prototokens.ErrTamper, "should pick up the rotated key")
+	st, err = m.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(ctx, st))
+}
+
+func TestKeyData(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := map[string][]byte{
+		"empty":     nil,
+		"not-pem":   []byte("snarf"),
+		"wrong-pem": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("snarf")}),
+		"garbage":   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("snarf")}),
+		"p384":      privatePEM(t, p384),
+		"ed25519":   privatePEM(t, edPriv),
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := New(func(_ context.Context) []byte { return tc })
+			require.ErrorIs(t, err, prototokens.ErrKeyData)
+		})
+	}
+	_, err = New(nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+type setupData struct {
+	pt *tokenpb.ProtoToken
+	st *tokenpb.SignedToken
+	m  prototokens.TokenManager
+}
+
+func privatePEM(t *testing.T, priv any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func setupTest(t *testing.T, testName string, opts ...prototokens.ManagerOpt) (*setupData, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keydata := privatePEM(t, priv)
+
+	m, err := New(func(_ context.Context) []byte { return keydata }, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := prototokens.New(
+		1*time.Hour,
+		prototokens.WithID(testName+"_id"),
+		prototokens.WithSID(testName+"_sid"),
+		prototokens.WithVendor([]byte(testName+"_vendor")),
+		prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := m.Sign(context.TODO(), pt)
+	if err != nil {
+		return nil, err
+	}
+	return &setupData{
+		pt: pt,
+		st: st,
+		m:  m,
+	}, nil
+}
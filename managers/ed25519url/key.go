@@ -0,0 +1,78 @@
+package ed25519url
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/lusis/prototokens"
+)
+
+// KeyDataFunc is a func that can return the seed passed to [ed25519.NewKeyFromSeed]
+type KeyDataFunc func(context.Context) []byte
+
+// KeyDataErrFunc is a func that can return the seed passed to [ed25519.NewKeyFromSeed] and an error
+// use this when the seed comes from something that can fail such as a secret backend
+type KeyDataErrFunc func(context.Context) ([]byte, error)
+
+// PublicKeyFunc is a func that can return the [ed25519.PublicKey] used to verify tokens
+type PublicKeyFunc func(context.Context) ed25519.PublicKey
+
+// errFunc adapts a [KeyDataFunc] to a [KeyDataErrFunc]
+func (kdf KeyDataFunc) errFunc() KeyDataErrFunc {
+	if kdf == nil {
+		return nil
+	}
+	return func(ctx context.Context) ([]byte, error) {
+		return kdf(ctx), nil
+	}
+}
+
+// key is either a seed that can sign and verify or a public key that can only verify
+// key material is fetched and validated on every use since the funcs can return anything at any time
+type key struct {
+	keyDataFunc   KeyDataErrFunc
+	publicKeyFunc PublicKeyFunc
+}
+
+// validate checks that the key material is usable
+func (k key) validate(ctx context.Context) error {
+	if k.keyDataFunc == nil && k.publicKeyFunc == nil {
+		return fmt.Errorf("%w: no key data provided", prototokens.ErrKeyData)
+	}
+	_, err := k.public(ctx)
+	return err
+}
+
+// private returns the private key or [prototokens.ErrVerifyOnly] for public keys
+func (k key) private(ctx context.Context) (ed25519.PrivateKey, error) {
+	if k.keyDataFunc == nil {
+		return nil, prototokens.ErrVerifyOnly
+	}
+	seed, err := k.keyDataFunc(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrKeyData, err)
+	}
+	// ed25519.NewKeyFromSeed panics on an invalid seed length
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: invalid seed size returned (want: %d have: %d)", prototokens.ErrKeyData, ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// public returns the public key used to verify signatures
+func (k key) public(ctx context.Context) (ed25519.PublicKey, error) {
+	if k.publicKeyFunc == nil {
+		priv, err := k.private(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return priv.Public().(ed25519.PublicKey), nil
+	}
+	pub := k.publicKeyFunc(ctx)
+	// ed25519.Verify panics on an invalid public key length
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: invalid public key size returned (want: %d have: %d)", prototokens.ErrKeyData, ed25519.PublicKeySize, len(pub))
+	}
+	return pub, nil
+}
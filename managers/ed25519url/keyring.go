@@ -0,0 +1,128 @@
+package ed25519url
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lusis/prototokens"
+)
+
+// Keyring holds multiple ed25519 keys by key id so keys can be rotated without invalidating outstanding tokens
+// a keyring with only public keys can only verify tokens
+// a key moves through the following states:
+// - added: used to verify tokens signed with its key id
+// - active: used to sign new tokens (and verify). only one key is active at a time and it cannot be a public key
+// - retired: no longer used to sign or verify
+// rotation with overlapping validity windows is then:
+// - [Keyring.Add] the new key so every verifier trusts it
+// - [Keyring.SetActive] the new key once it has been distributed
+// - [Keyring.Retire] the old key once every token it signed has expired
+type Keyring struct {
+	mu      sync.RWMutex
+	active  *string
+	keys    map[string]key
+	retired map[string]struct{}
+}
+
+// NewKeyring returns a new [ed25519url.Keyring]
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys:    map[string]key{},
+		retired: map[string]struct{}{},
+	}
+}
+
+// Add adds a key to the keyring used to verify tokens with the provided key id
+// the empty id matches tokens signed before key ids were used such as by [New]
+func (kr *Keyring) Add(keyID string, keyDataFunc KeyDataFunc) error {
+	return kr.add(keyID, key{keyDataFunc: keyDataFunc.errFunc()})
+}
+
+// AddWithKeyDataErrFunc adds a key to the keyring using a [KeyDataErrFunc]
+func (kr *Keyring) AddWithKeyDataErrFunc(keyID string, keyDataFunc KeyDataErrFunc) error {
+	return kr.add(keyID, key{keyDataFunc: keyDataFunc})
+}
+
+// AddPublicKey adds a public key to the keyring used to verify tokens with the provided key id
+// public keys can never be the active key
+func (kr *Keyring) AddPublicKey(keyID string, publicKeyFunc PublicKeyFunc) error {
+	return kr.add(keyID, key{publicKeyFunc: publicKeyFunc})
+}
+
+// SetActive sets the key used to sign new tokens
+func (kr *Keyring) SetActive(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	k, ok := kr.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", prototokens.ErrKeyData, keyID)
+	}
+	if k.keyDataFunc == nil {
+		return fmt.Errorf("%w: key %q", prototokens.ErrVerifyOnly, keyID)
+	}
+	kr.active = &keyID
+	return nil
+}
+
+// Retire removes a key so it is no longer used to verify tokens
+// the active key cannot be retired and a retired key id can never be added again
+func (kr *Keyring) Retire(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[keyID]; !ok {
+		return fmt.Errorf("%w: unknown key %q", prototokens.ErrKeyData, keyID)
+	}
+	if kr.active != nil && *kr.active == keyID {
+		return fmt.Errorf("%w: cannot retire the active key %q", prototokens.ErrKeyData, keyID)
+	}
+	delete(kr.keys, keyID)
+	kr.retired[keyID] = struct{}{}
+	return nil
+}
+
+func (kr *Keyring) add(keyID string, k key) error {
+	if err := k.validate(context.Background()); err != nil {
+		return err
+	}
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if _, ok := kr.keys[keyID]; ok {
+		return fmt.Errorf("%w: key %q", prototokens.ErrOverwrite, keyID)
+	}
+	if _, ok := kr.retired[keyID]; ok {
+		return fmt.Errorf("%w: key %q has been retired", prototokens.ErrKeyData, keyID)
+	}
+	kr.keys[keyID] = k
+	return nil
+}
+
+// empty reports if the keyring has no keys
+func (kr *Keyring) empty() bool {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	return len(kr.keys) == 0
+}
+
+// signer returns the active key id and key
+func (kr *Keyring) signer() (string, key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	if kr.active == nil {
+		return "", key{}, fmt.Errorf("%w: no active key", prototokens.ErrVerifyOnly)
+	}
+	return *kr.active, kr.keys[*kr.active], nil
+}
+
+// verifier returns the key for a non-retired key id
+// key ids come from the token so an unknown or retired id is the token's fault and is reported as [prototokens.ErrTamper]
+// rather than [prototokens.ErrKeyData] which is kept for failures loading our own key material
+func (kr *Keyring) verifier(keyID string) (key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+	k, ok := kr.keys[keyID]
+	if !ok {
+		return key{}, fmt.Errorf("%w: unknown key %q", prototokens.ErrTamper, keyID)
+	}
+	return k, nil
+}
@@ -0,0 +1,131 @@
+package ed25519url
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	kr := NewKeyring()
+	_, err := NewWithKeyring(kr)
+	require.ErrorIs(t, err, prototokens.ErrKeyData, "should require a key")
+
+	require.NoError(t, kr.Add("one", newKeyDataFunc(t)))
+	require.NoError(t, kr.SetActive("one"))
+	m, err := NewWithKeyring(kr)
+	require.NoError(t, err)
+
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	first, err := m.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.Equal(t, "one", first.GetKeyId(), "should sign with the active key")
+	require.NoError(t, m.Validate(ctx, first))
+
+	// overlapping window where both keys verify
+	require.NoError(t, kr.Add("two", newKeyDataFunc(t)))
+	require.NoError(t, kr.SetActive("two"))
+	second, err := m.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.Equal(t, "two", second.GetKeyId(), "should sign with the new active key")
+	require.NoError(t, m.Validate(ctx, first), "tokens signed with the old key should still verify")
+	require.NoError(t, m.Validate(ctx, second))
+
+	// key ids aren't signed but changing them just selects the wrong key
+	swapped := proto.Clone(second).(*tokenpb.SignedToken)
+	swapped.KeyId = "one"
+	require.ErrorIs(t, m.Validate(ctx, swapped), prototokens.ErrTamper)
+	swapped.KeyId = "unknown"
+	err = m.Validate(ctx, swapped)
+	require.ErrorIs(t, err, prototokens.ErrTamper)
+	require.NotErrorIs(t, err, prototokens.ErrKeyData, "unknown key ids are the token's fault")
+	var ve *prototokens.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, prototokens.ReasonInvalidSignature, ve.Reason)
+
+	require.ErrorIs(t, kr.Retire("two"), prototokens.ErrKeyData, "should not retire the active key")
+	require.NoError(t, kr.Retire("one"))
+	err = m.Validate(ctx, first)
+	require.ErrorIs(t, err, prototokens.ErrInvalidSignature, "retired keys should not verify")
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, prototokens.ReasonInvalidSignature, ve.Reason)
+	require.NoError(t, m.Validate(ctx, second))
+	require.ErrorIs(t, kr.Add("one", newKeyDataFunc(t)), prototokens.ErrKeyData, "retired keys cannot be re-added")
+}
+
+func TestKeyringErrors(t *testing.T) {
+	kr := NewKeyring()
+	require.ErrorIs(t, kr.Add("nil", nil), prototokens.ErrKeyData)
+	require.ErrorIs(t, kr.Add("short", func(_ context.Context) []byte { return []byte("short") }), prototokens.ErrKeyData)
+	require.NoError(t, kr.Add("one", newKeyDataFunc(t)))
+	require.ErrorIs(t, kr.Add("one", newKeyDataFunc(t)), prototokens.ErrOverwrite)
+	require.ErrorIs(t, kr.SetActive("unknown"), prototokens.ErrKeyData)
+	require.ErrorIs(t, kr.Retire("unknown"), prototokens.ErrKeyData)
+	_, err := NewWithKeyring(nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+func TestKeyringLegacy(t *testing.T) {
+	// tokens signed by a single key manager have no key id
+	ctx := context.Background()
+	kdf := newKeyDataFunc(t)
+	data, err := setupTest(t.Name(), kdf)
+	require.NoError(t, err)
+	require.Empty(t, data.st.GetKeyId())
+
+	kr := NewKeyring()
+	require.NoError(t, kr.Add("", kdf))
+	require.NoError(t, kr.Add("new", newKeyDataFunc(t)))
+	require.NoError(t, kr.SetActive("new"))
+	m, err := NewWithKeyring(kr)
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(ctx, data.st), "legacy tokens should verify with the empty key id")
+}
+
+func TestKeyringVerifyOnly(t *testing.T) {
+	ctx := context.Background()
+	kdf := newKeyDataFunc(t)
+	signers := NewKeyring()
+	require.NoError(t, signers.Add("one", kdf))
+	require.NoError(t, signers.SetActive("one"))
+	signer, err := NewWithKeyring(signers)
+	require.NoError(t, err)
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	st, err := signer.Sign(ctx, pt)
+	require.NoError(t, err)
+
+	verifiers := NewKeyring()
+	require.NoError(t, verifiers.AddPublicKey("one", func(ctx context.Context) ed25519.PublicKey {
+		return ed25519.NewKeyFromSeed(kdf(ctx)).Public().(ed25519.PublicKey)
+	}))
+	require.ErrorIs(t, verifiers.SetActive("one"), prototokens.ErrVerifyOnly, "public keys cannot be active")
+	require.ErrorIs(t, verifiers.AddPublicKey("short", func(_ context.Context) ed25519.PublicKey { return nil }), prototokens.ErrKeyData)
+	verifier, err := NewWithKeyring(verifiers)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Validate(ctx, st))
+	_, err = verifier.Sign(ctx, pt)
+	require.ErrorIs(t, err, prototokens.ErrVerifyOnly)
+}
+
+func newKeyDataFunc(t *testing.T) KeyDataFunc {
+	t.Helper()
+	keydata := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, keydata)
+	require.NoError(t, err)
+	return func(_ context.Context) []byte {
+		return keydata
+	}
+}
@@ -3,9 +3,7 @@ package ed25519url
 import (
 	"context"
 	"crypto/ed25519"
-	"encoding/base64"
 	"fmt"
-	"time"
 
 	"github.com/lusis/prototokens"
 	"github.com/lusis/prototokens/internal"
@@ -15,40 +13,79 @@ import (
 )
 
 // Manager is an implementation of [prototokens.TokenManager] that:
-// - signs tokens with ed25519 with [keyDataFunc] returning the seed that will be passed to [ed25519.NewFromSeed]
-// - encodes/decodes with [base64.RawURLEncoding.EncodeToString]
+// - signs tokens with ed25519 with a [KeyDataFunc] or [KeyDataErrFunc] returning the seed that will be passed to [ed25519.NewKeyFromSeed]
+// - encodes/decodes with the configured [prototokens.Encoder] (url-safe base64 by default)
+// - checks revocation with an optional [prototokens.RevocationStorer]
+// - optionally signs and verifies with a [Keyring] selecting keys by [tokenpb.SignedToken] key_id
+// - can verify without signing material when created with [NewVerifier]
 type Manager struct {
-	*prototokens.UnimplementedTokenManager
-	keyDataFunc KeyDataFunc
+	*prototokens.Validator
+	key     key
+	keyring *Keyring
+	cfg     *prototokens.ManagerConfig
 }
 
-// KeyDataFunc is a func that can return the seed passed to [ed25519.NewFromSeed] and optional error
-type KeyDataFunc func(context.Context) []byte
+// New returns a new [ed25519url.Manager]
+func New(keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	return NewWithKeyDataErrFunc(keyDataFunc.errFunc(), opts...)
+}
 
-// New returns a new [sharedkey.Manager]
-func New(keyDataFunc KeyDataFunc) (*Manager, error) {
+// NewWithKeyDataErrFunc returns a new [ed25519url.Manager] using a [KeyDataErrFunc]
+// errors and invalid seeds returned when signing or verifying are returned as [prototokens.ErrKeyData]
+func NewWithKeyDataErrFunc(keyDataFunc KeyDataErrFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
 	// get the seed an make sure it's valid
-	seed := keyDataFunc(context.Background())
-	seedlen := len(seed)
-	if seedlen != ed25519.SeedSize {
-		return nil, fmt.Errorf("%w: invalid seed size returned (want: %d have: %d)", prototokens.ErrKeyData, ed25519.SeedSize, seedlen)
+	k := key{keyDataFunc: keyDataFunc}
+	if err := k.validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Manager{keyDataFunc: keyDataFunc}, nil
+	return newManager(k, nil, cfg), nil
 }
 
-// GetValidatedToken turns a [tokenpb.SignedToken] into a [tokenpb.ProtoToken] after validation
-func (skm *Manager) GetValidatedToken(ctx context.Context, token *tokenpb.SignedToken) (*tokenpb.ProtoToken, error) {
-	ctx, span := internal.StartSpan(ctx, "GetValidatedToken")
-	defer span.End()
-	if err := skm.Validate(ctx, token); err != nil {
-		return nil, fmt.Errorf("%w: %w", prototokens.ErrNotValid, err)
+// NewVerifier returns a new [ed25519url.Manager] that can only verify tokens
+// Sign returns [prototokens.ErrVerifyOnly] while every other method works normally
+// this allows services that only validate tokens to never possess signing material
+func NewVerifier(publicKeyFunc PublicKeyFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	k := key{publicKeyFunc: publicKeyFunc}
+	if err := k.validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newManager(k, nil, cfg), nil
+}
+
+// NewWithKeyring returns a new [ed25519url.Manager] that signs with the active key in the [Keyring]
+// and verifies with whichever non-retired key matches the token's key_id
+// a keyring without an active key can only verify tokens
+func NewWithKeyring(keyring *Keyring, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	if keyring == nil {
+		return nil, fmt.Errorf("%w: keyring cannot be nil", prototokens.ErrKeyData)
+	}
+	if keyring.empty() {
+		return nil, fmt.Errorf("%w: keyring has no keys", prototokens.ErrKeyData)
 	}
-	pt := &tokenpb.ProtoToken{}
-	if err := proto.Unmarshal(token.GetPrototoken(), pt); err != nil {
-		return nil, fmt.Errorf("%w: %w", prototokens.ErrUnmarshal, err)
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
 	}
-	return pt, nil
+
+	return newManager(key{}, keyring, cfg), nil
+}
+
+func newManager(k key, keyring *Keyring, cfg *prototokens.ManagerConfig) *Manager {
+	m := &Manager{key: k, keyring: keyring, cfg: cfg}
+	m.Validator = prototokens.NewValidator(cfg, m.open)
+	return m
 }
 
 // Sign signs the token
@@ -62,107 +99,81 @@ func (skm *Manager) Sign(ctx context.Context, pt *tokenpb.ProtoToken) (*tokenpb.
 	}
 	span.AddEvent("marshal end")
 
-	sig, err := skm.sign(ctx, b)
+	keyID, sig, err := skm.sign(ctx, b)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", prototokens.ErrSign, err)
 	}
 	st := &tokenpb.SignedToken{
 		Signature:  sig,
 		Prototoken: b,
+		KeyId:      keyID,
 	}
 	return st, nil
 }
 
-// ValidFor checks if a token is valid for a specific usage
-func (skm *Manager) ValidFor(ctx context.Context, st *tokenpb.SignedToken, usage tokenpb.TokenUsages) error {
-	ctx, span := internal.StartSpan(ctx, "ValidFor")
+// RevokeToken revokes a token via the configured [prototokens.RevocationStorer]
+// the revocation id is determined by [prototokens.RevocationID]
+func (skm *Manager) RevokeToken(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeToken")
 	defer span.End()
-	tok, err := skm.GetValidatedToken(ctx, st)
-	if err != nil {
-		return err
-	}
-	for _, u := range tok.GetUsages() {
-		if u == usage {
-			// got a hit
-			return nil
-		}
-	}
-	return prototokens.ErrNotValidForUsage
+	return skm.cfg.Revoke(ctx, pt)
 }
 
-// Validate checks if the token is valid
-// we do the validation in layers based on how expensive it is to validate
-// - unmarshal the token bytes. we need to do that for the later checks. failure means its not valid
-// - validate the signature to ensure the message hasn't been tampered with
-// - check timestamps in the token now that we know we can trust it
-func (skm *Manager) Validate(ctx context.Context, st *tokenpb.SignedToken) error {
-	ctx, span := internal.StartSpan(ctx, "Validate")
-	defer span.End()
-	now := time.Now().UTC()
-	tok := &tokenpb.ProtoToken{}
-	if err := proto.Unmarshal(st.GetPrototoken(), tok); err != nil {
-		return fmt.Errorf("%w: %w", prototokens.ErrUnmarshal, err)
-	}
-	// validate the signature
-	if err := skm.verify(ctx, st.GetSignature(), st.GetPrototoken()); err != nil {
-		return fmt.Errorf("%w: %w", prototokens.ErrInvalidSignature, err)
-	}
-
-	// we know the token is valid so we can do our other checks
-	nvb := tok.GetTimestamps().GetNotValidBefore().AsTime().UTC()
-	if now.Before(nvb) {
-		return prototokens.ErrNotYetValid
-	}
-
-	nva := tok.GetTimestamps().GetNotValidAfter().AsTime().UTC()
-	if now.After(nva) {
-		return prototokens.ErrNoLongerValid
-	}
-
-	return nil
-}
-
-// Encode encodes a signed token as a url-safe string
+// Encode encodes a signed token with the configured [prototokens.Encoder]
 func (skm *Manager) Encode(ctx context.Context, st *tokenpb.SignedToken) (string, error) {
 	_, span := internal.StartSpan(ctx, "Encode")
 	defer span.End()
-
-	// marshal first
-	b, err := proto.Marshal(st)
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
-	}
-	return base64.RawURLEncoding.EncodeToString(b), nil
+	return skm.cfg.Encode(st)
 }
 
-// Decode decodes a signed token from a url-safe string representation
+// Decode decodes a signed token with the configured [prototokens.Encoder]
 func (skm *Manager) Decode(ctx context.Context, s string) (*tokenpb.SignedToken, error) {
 	_, span := internal.StartSpan(ctx, "Decode")
 	defer span.End()
+	return skm.cfg.Decode(s)
+}
 
-	st := &tokenpb.SignedToken{}
-	b, err := base64.RawURLEncoding.DecodeString(s)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", prototokens.ErrDecode, err)
+// sign signs the data with the active key returning the id of the key used
+func (skm *Manager) sign(ctx context.Context, data []byte) (string, []byte, error) {
+	keyID, k := "", skm.key
+	if skm.keyring != nil {
+		var err error
+		keyID, k, err = skm.keyring.signer()
+		if err != nil {
+			return "", nil, err
+		}
 	}
-	if err := proto.Unmarshal(b, st); err != nil {
-		return nil, fmt.Errorf("%w: %w", prototokens.ErrUnmarshal, err)
+	priv, err := k.private(ctx)
+	if err != nil {
+		return "", nil, err
 	}
-	return st, nil
-}
-
-func (skm *Manager) sign(ctx context.Context, data []byte) ([]byte, error) { // nolint: unparam
-	seed := skm.keyDataFunc(ctx)
-
-	priv := ed25519.NewKeyFromSeed(seed)
 	sig := ed25519.Sign(priv, data)
-	return sig, nil
+	return keyID, sig, nil
 }
 
-func (skm *Manager) verify(ctx context.Context, sig []byte, data []byte) error {
-	seed := skm.keyDataFunc(ctx)
+// open verifies the signature over the raw token bytes returning them once they can be trusted
+func (skm *Manager) open(ctx context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	if err := skm.verify(ctx, st.GetKeyId(), st.GetSignature(), st.GetPrototoken()); err != nil {
+		return nil, err
+	}
+	return st.GetPrototoken(), nil
+}
 
-	pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+// verify verifies the signature with the key for the key id
+// the key id is ignored without a keyring
+func (skm *Manager) verify(ctx context.Context, keyID string, sig []byte, data []byte) error {
+	k := skm.key
+	if skm.keyring != nil {
+		var err error
+		k, err = skm.keyring.verifier(keyID)
+		if err != nil {
+			return err
+		}
+	}
+	pub, err := k.public(ctx)
+	if err != nil {
+		return err
+	}
 	if verified := ed25519.Verify(pub, data, sig); !verified {
 		return prototokens.ErrTamper
 	}
@@ -3,15 +3,22 @@ package ed25519url
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
+	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/lusis/prototokens"
 	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+	"github.com/lusis/prototokens/prototokenstest"
+	"github.com/lusis/prototokens/storers/memory"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +64,28 @@ func TestNoLongerValid(t *testing.T) {
 	require.ErrorIs(t, err, prototokens.ErrNoLongerValid)
 }
 
+func TestClock(t *testing.T) {
+	// a token from the past can be replayed by validating with a clock set to when it was issued
+	issued := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(issued)
+	data, err := setupTest(t.Name(), nil, prototokens.WithClock(fc))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	pt, err := prototokens.New(time.Hour, prototokens.WithTokenClock(fc))
+	require.NoError(t, err)
+	st, err := data.m.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.NoError(t, data.m.Validate(ctx, st))
+
+	fc.Advance(59 * time.Minute)
+	require.NoError(t, data.m.Validate(ctx, st))
+	fc.Advance(2 * time.Minute)
+	require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNoLongerValid)
+	fc.Set(issued.Add(-time.Second))
+	require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNotYetValid)
+}
+
 func TestHappyPath(t *testing.T) {
 	data, err := setupTest(t.Name(), nil)
 	require.NoError(t, err)
@@ -93,6 +122,197 @@ func TestEncodeDecode(t *testing.T) {
 	require.True(t, proto.Equal(st, dec), "original and decoded signed token should be the same")
 	require.True(t, proto.Equal(vtok, pt), "original and decoded token should be the same")
 }
+
+func TestValidateAndParse(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	enc, err := data.m.Encode(ctx, data.st)
+	require.NoError(t, err)
+
+	pt, err := data.m.ValidateAndParse(ctx, enc)
+	require.NoError(t, err, "should be valid without requirements")
+	require.True(t, proto.Equal(data.pt, pt), "original and parsed token should be the same")
+	pt, err = data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), prototokens.RequireSID(data.pt.GetSid()))
+	require.NoError(t, err, "should meet requirements")
+	require.True(t, proto.Equal(data.pt, pt), "original and parsed token should be the same")
+
+	var ve *prototokens.ValidationError
+	_, err = data.m.ValidateAndParse(ctx, enc, prototokens.RequireSID("other"))
+	require.ErrorIs(t, err, prototokens.ErrRequirement)
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, prototokens.ReasonRequirement, ve.Reason)
+
+	_, err = data.m.ValidateAndParse(ctx, "!!!")
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, prototokens.ReasonMalformed, ve.Reason)
+
+	other, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	otherEnc, err := other.m.Encode(ctx, other.st)
+	require.NoError(t, err)
+	_, err = data.m.ValidateAndParse(ctx, otherEnc)
+	require.ErrorIs(t, err, prototokens.ErrInvalidSignature)
+}
+
+func TestRequiredClaims(t *testing.T) {
+	// two services sharing a key should still refuse each other's tokens
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := io.ReadFull(rand.Reader, seed)
+	require.NoError(t, err)
+	keyDataFunc := func(_ context.Context) []byte { return seed }
+	billing, err := New(keyDataFunc, prototokens.WithRequiredIssuer("billing"), prototokens.WithRequiredAudience("billing"))
+	require.NoError(t, err)
+	admin, err := New(keyDataFunc, prototokens.WithRequiredAudience("admin"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	pt, err := prototokens.New(time.Hour, prototokens.WithIssuer("billing"), prototokens.WithAudience("billing"), prototokens.WithSubject("user:1"))
+	require.NoError(t, err)
+	st, err := billing.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.NoError(t, billing.Validate(ctx, st))
+	require.ErrorIs(t, admin.Validate(ctx, st), prototokens.ErrClaimMismatch)
+
+	vt, err := billing.GetValidatedToken(ctx, st)
+	require.NoError(t, err)
+	require.Equal(t, "user:1", vt.GetSubject())
+}
+
+func TestValidForScopes(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	pt, err := prototokens.New(time.Hour, prototokens.WithScopes("repo:*", "org:read"))
+	require.NoError(t, err)
+	st, err := data.m.Sign(ctx, pt)
+	require.NoError(t, err)
+
+	require.NoError(t, data.m.ValidForScopes(ctx, st, "repo:read", "org:read"))
+	err = data.m.ValidForScopes(ctx, st, "repo:read", "org:write")
+	require.ErrorIs(t, err, prototokens.ErrNotValidForScopes)
+	require.ErrorContains(t, err, "org:write")
+	require.ErrorIs(t, data.m.ValidForScopes(ctx, data.st, "repo:read"), prototokens.ErrNotValidForScopes, "tokens without scopes have none")
+}
+
+func TestValidForAllAny(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := io.ReadFull(rand.Reader, seed)
+	require.NoError(t, err)
+	var calls int
+	m, err := NewWithKeyDataErrFunc(func(_ context.Context) ([]byte, error) {
+		calls++
+		return seed, nil
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	pt, err := prototokens.New(time.Hour, prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE))
+	require.NoError(t, err)
+	st, err := m.Sign(ctx, pt)
+	require.NoError(t, err)
+
+	calls = 0
+	require.NoError(t, m.ValidForAny(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+	require.Equal(t, 1, calls, "should verify once per call")
+	require.NoError(t, m.ValidForAll(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE))
+
+	err = m.ValidForAll(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE, tokenpb.TokenUsages_TOKEN_USAGES_ROTATION)
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+	require.ErrorContains(t, err, "TOKEN_USAGES_MACHINE, TOKEN_USAGES_ROTATION")
+	err = m.ValidForAny(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE, tokenpb.TokenUsages_TOKEN_USAGES_ROTATION)
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+	require.ErrorIs(t, m.ValidForAny(ctx, st), prototokens.ErrNotValidForUsage, "no usages should fail closed")
+}
+
+func TestVendorMessage(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	pt, err := prototokens.New(time.Hour, prototokens.WithVendorMessage(wrapperspb.String("enterprise")))
+	require.NoError(t, err)
+	st, err := data.m.Sign(ctx, pt)
+	require.NoError(t, err)
+	enc, err := data.m.Encode(ctx, st)
+	require.NoError(t, err)
+
+	vt, err := data.m.ValidateAndParse(ctx, enc)
+	require.NoError(t, err)
+	plan, err := prototokens.VendorAs[*wrapperspb.StringValue](vt)
+	require.NoError(t, err)
+	require.Equal(t, "enterprise", plan.GetValue())
+	_, err = prototokens.VendorAs[*wrapperspb.Int64Value](vt)
+	require.ErrorIs(t, err, prototokens.ErrVendorMessageType)
+}
+
+func TestEncoders(t *testing.T) {
+	for name, enc := range map[string]prototokens.Encoder{
+		"base64std":       prototokens.Base64StdEncoder,
+		"base32crockford": prototokens.Base32CrockfordEncoder,
+		"base58":          prototokens.Base58Encoder,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := setupTest(t.Name(), nil, prototokens.WithEncoder(enc))
+			require.NoError(t, err)
+			s, err := data.m.Encode(context.Background(), data.st)
+			require.NoError(t, err, "should encode")
+			b, err := enc.DecodeString(s)
+			require.NoError(t, err, "should be encoded with the configured encoder")
+			require.NotEmpty(t, b)
+			dec, err := data.m.Decode(context.Background(), s)
+			require.NoError(t, err, "should decode")
+			require.True(t, proto.Equal(data.st, dec), "original and decoded signed token should be the same")
+		})
+	}
+}
+
+func TestPrefixedEncoder(t *testing.T) {
+	pe, err := prototokens.NewPrefixedEncoder("myco_live", nil)
+	require.NoError(t, err)
+	data, err := setupTest(t.Name(), nil, prototokens.WithEncoder(pe))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	s, err := data.m.Encode(ctx, data.st)
+	require.NoError(t, err, "should encode")
+	dec, err := data.m.Decode(ctx, s)
+	require.NoError(t, err, "should decode")
+	require.True(t, proto.Equal(data.st, dec), "original and decoded signed token should be the same")
+
+	_, err = data.m.Decode(ctx, "myco_test"+strings.TrimPrefix(s, "myco_live"))
+	require.ErrorIs(t, err, prototokens.ErrDecode)
+	require.ErrorIs(t, err, prototokens.ErrPrefix)
+	typo := []byte(s)
+	typo[len("myco_live_")] ^= 'a' ^ 'b'
+	_, err = data.m.Decode(ctx, string(typo))
+	require.ErrorIs(t, err, prototokens.ErrDecode)
+	require.ErrorIs(t, err, prototokens.ErrChecksum)
+}
+
+func TestValidationReasons(t *testing.T) {
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	reason := func(err error) prototokens.ValidationReason {
+		var ve *prototokens.ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Empty(t, ve.ID, "unverified tokens should not report an id")
+		return ve.Reason
+	}
+
+	st := proto.Clone(data.st).(*tokenpb.SignedToken)
+	st.Signature[0] ^= 0xff
+	err = data.m.Validate(ctx, st)
+	require.ErrorIs(t, err, prototokens.ErrInvalidSignature)
+	require.Equal(t, prototokens.ReasonInvalidSignature, reason(err))
+
+	st = proto.Clone(data.st).(*tokenpb.SignedToken)
+	st.Prototoken = []byte("[]")
+	require.Equal(t, prototokens.ReasonInvalidSignature, reason(data.m.Validate(ctx, st)), "unauthenticated bytes are never parsed")
+
+	_, err = data.m.Decode(ctx, "!!!")
+	require.Equal(t, prototokens.ReasonMalformed, reason(err))
+}
+
 func TestValidation(t *testing.T) {
 	data, err := setupTest(t.Name(), nil)
 	require.NoError(t, err)
@@ -168,13 +388,22 @@ func TestValidation(t *testing.T) {
 	}
 
 	t.Run("invalid-token", func(t *testing.T) {
-		d, err := setupTest(t.Name(), nil)
+		seed := make([]byte, ed25519.SeedSize)
+		_, err := io.ReadFull(rand.Reader, seed)
+		require.NoError(t, err)
+		d, err := setupTest(t.Name(), func(_ context.Context) []byte { return seed })
 		require.NoError(t, err)
 
-		// we need the signed token and the manager for this test
+		// unauthenticated bytes are rejected before they are parsed
 		st, m := d.st, d.m
 		st.Prototoken = []byte("[]")
 		err = m.Validate(context.Background(), st)
+		require.ErrorIs(t, err, prototokens.ErrInvalidSignature)
+		require.NotErrorIs(t, err, prototokens.ErrUnmarshal)
+
+		// authenticated garbage still fails to unmarshal
+		st.Signature = ed25519.Sign(ed25519.NewKeyFromSeed(seed), st.Prototoken)
+		err = m.Validate(context.Background(), st)
 		require.ErrorIs(t, err, prototokens.ErrUnmarshal)
 	})
 }
@@ -188,6 +417,211 @@ func TestInvalid(t *testing.T) {
 	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
 }
 
+func TestKeyDataErrFunc(t *testing.T) {
+	ctx := context.Background()
+	keydata := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, keydata)
+	require.NoError(t, err)
+	var backendErr error
+	var seed = keydata
+	m, err := NewWithKeyDataErrFunc(func(_ context.Context) ([]byte, error) {
+		return seed, backendErr
+	})
+	require.NoError(t, err)
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	st, err := m.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(ctx, st))
+
+	// backend failures after creation
+	backendErr = fmt.Errorf("snarf")
+	_, err = m.Sign(ctx, pt)
+	require.ErrorIs(t, err, prototokens.ErrSign)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	require.ErrorIs(t, m.Validate(ctx, st), prototokens.ErrKeyData)
+
+	// invalid seeds after creation should error instead of panic
+	backendErr = nil
+	seed = []byte("short")
+	require.NotPanics(t, func() {
+		_, err = m.Sign(ctx, pt)
+	})
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	require.NotPanics(t, func() {
+		err = m.Validate(ctx, st)
+	})
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+
+	// and errors on creation
+	_, err = NewWithKeyDataErrFunc(func(_ context.Context) ([]byte, error) {
+		return nil, fmt.Errorf("snarf")
+	})
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	_, err = New(func(_ context.Context) []byte { return []byte("short") })
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+func TestVerifier(t *testing.T) {
+	rs := &testRevocationStorer{}
+	data, err := setupTest(t.Name(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	signer := data.m.(*Manager)
+	pub, err := signer.key.public(ctx)
+	require.NoError(t, err)
+
+	m, err := NewVerifier(func(_ context.Context) ed25519.PublicKey { return pub }, prototokens.WithRevocationStorer(rs))
+	require.NoError(t, err)
+	_, err = m.Sign(ctx, data.pt)
+	require.ErrorIs(t, err, prototokens.ErrSign)
+	require.ErrorIs(t, err, prototokens.ErrVerifyOnly)
+
+	require.NoError(t, m.Validate(ctx, data.st))
+	require.NoError(t, m.ValidFor(ctx, data.st, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+	vt, err := m.GetValidatedToken(ctx, data.st)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(data.pt, vt))
+	require.NoError(t, m.RevokeToken(ctx, vt), "verifiers should still be able to revoke")
+	require.ErrorIs(t, m.Validate(ctx, data.st), prototokens.ErrTokenRevoked)
+
+	other, err := setupTest(t.Name()+"_other", nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, m.Validate(ctx, other.st), prototokens.ErrTamper, "should not verify tokens from other keys")
+
+	_, err = NewVerifier(func(_ context.Context) ed25519.PublicKey { return pub[:4] })
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	_, err = NewVerifier(nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+type testRevocationStorer struct {
+	mu      sync.Mutex
+	revoked map[string]struct{}
+	err     error
+}
+
+func (trs *testRevocationStorer) Revoke(_ context.Context, id string) error {
+	trs.mu.Lock()
+	defer trs.mu.Unlock()
+	if trs.revoked == nil {
+		trs.revoked = map[string]struct{}{}
+	}
+	trs.revoked[id] = struct{}{}
+	return nil
+}
+
+func (trs *testRevocationStorer) CheckRevocation(_ context.Context, id string) error {
+	trs.mu.Lock()
+	defer trs.mu.Unlock()
+	if trs.err != nil {
+		return trs.err
+	}
+	if _, ok := trs.revoked[id]; ok {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+func TestRevocation(t *testing.T) {
+	t.Run("no-storer", func(t *testing.T) {
+		data, err := setupTest(t.Name(), nil)
+		require.NoError(t, err)
+		err = data.m.RevokeToken(context.Background(), data.pt)
+		require.ErrorIs(t, err, prototokens.ErrUnimplemented)
+		require.NoError(t, data.m.Validate(context.Background(), data.st), "should not check revocation without a storer")
+	})
+	t.Run("revoked", func(t *testing.T) {
+		rs := &testRevocationStorer{}
+		data, err := setupTest(t.Name(), nil, prototokens.WithRevocationStorer(rs))
+		require.NoError(t, err)
+		ctx := context.Background()
+		require.NoError(t, data.m.Validate(ctx, data.st), "should be valid before revocation")
+		require.NoError(t, data.m.RevokeToken(ctx, data.pt), "should revoke")
+		require.Contains(t, rs.revoked, data.pt.GetId(), "should revoke by id")
+
+		require.ErrorIs(t, data.m.Validate(ctx, data.st), prototokens.ErrTokenRevoked)
+		_, err = data.m.GetValidatedToken(ctx, data.st)
+		require.ErrorIs(t, err, prototokens.ErrTokenRevoked)
+		err = data.m.ValidFor(ctx, data.st, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN)
+		require.ErrorIs(t, err, prototokens.ErrTokenRevoked)
+		var ve *prototokens.ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, prototokens.ReasonRevoked, ve.Reason)
+		require.Equal(t, data.pt.GetId(), ve.ID)
+	})
+	t.Run("revoked-without-id", func(t *testing.T) {
+		rs := &testRevocationStorer{}
+		data, err := setupTest(t.Name(), nil, prototokens.WithRevocationStorer(rs))
+		require.NoError(t, err)
+		ctx := context.Background()
+		pt := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		pt.Id = ""
+		st, err := data.m.Sign(ctx, pt)
+		require.NoError(t, err)
+		require.NoError(t, data.m.Validate(ctx, st), "should be valid before revocation")
+
+		// round trip through the validated token like a caller would
+		vt, err := data.m.GetValidatedToken(ctx, st)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.RevokeToken(ctx, vt), prototokens.ErrNoRevocationID, "tokens without an id can only be revoked in bulk")
+		require.Empty(t, rs.revoked)
+		require.NoError(t, data.m.Validate(ctx, st), "the per-id check should be skipped without an id")
+	})
+	t.Run("bulk", func(t *testing.T) {
+		rs, err := memory.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = rs.Close() })
+		data, err := setupTest(t.Name(), nil, prototokens.WithRevocationStorer(rs))
+		require.NoError(t, err)
+		ctx := context.Background()
+		require.NoError(t, data.m.Validate(ctx, data.st))
+
+		// tokens issued before the cutoff for the sid are revoked but new ones are not
+		old := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		old.Timestamps.NotValidBefore = timestamppb.New(time.Now().Add(-1 * time.Minute))
+		old.Timestamps.IssuedAt = old.Timestamps.NotValidBefore
+		oldSigned, err := data.m.Sign(ctx, old)
+		require.NoError(t, err)
+		// issued before the cutoff but only valid in the future
+		future, err := prototokens.New(time.Hour, prototokens.WithSID(data.pt.GetSid()), prototokens.WithNotValidBefore(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		require.NoError(t, rs.RevokeIssuedBefore(ctx, data.pt.GetSid(), time.Now()))
+		_, err = data.m.GetValidatedToken(ctx, oldSigned)
+		require.ErrorIs(t, err, prototokens.ErrTokenRevoked)
+		// validation stops at not yet valid so check revocation directly
+		cfg := &prototokens.ManagerConfig{RevocationStorer: rs}
+		require.ErrorIs(t, cfg.CheckRevocation(ctx, future), prototokens.ErrTokenRevoked, "cutoffs should use issued_at")
+		fresh, err := prototokens.New(time.Hour, prototokens.WithSID(data.pt.GetSid()))
+		require.NoError(t, err)
+		freshSigned, err := data.m.Sign(ctx, fresh)
+		require.NoError(t, err)
+		require.NoError(t, data.m.Validate(ctx, freshSigned), "tokens issued after the cutoff should be valid")
+
+		// revoking the sid revokes everything
+		require.NoError(t, rs.RevokeSID(ctx, data.pt.GetSid()))
+		_, err = data.m.GetValidatedToken(ctx, freshSigned)
+		require.ErrorIs(t, err, prototokens.ErrTokenRevoked)
+
+		// global cutoffs apply to every sid
+		other, err := setupTest(t.Name()+"_other", nil, prototokens.WithRevocationStorer(rs))
+		require.NoError(t, err)
+		require.NoError(t, other.m.Validate(ctx, other.st))
+		require.NoError(t, rs.RevokeIssuedBefore(ctx, "", time.Now().Add(time.Second)))
+		require.ErrorIs(t, other.m.Validate(ctx, other.st), prototokens.ErrTokenRevoked)
+	})
+	t.Run("storer-error", func(t *testing.T) {
+		rs := &testRevocationStorer{err: fmt.Errorf("snarf")}
+		data, err := setupTest(t.Name(), nil, prototokens.WithRevocationStorer(rs))
+		require.NoError(t, err)
+		err = data.m.Validate(context.Background(), data.st)
+		require.ErrorIs(t, err, prototokens.ErrRevocationCheck, "should fail closed")
+		var ve *prototokens.ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, prototokens.ReasonRevocationCheck, ve.Reason)
+	})
+}
+
 type setupData struct {
 	pt *tokenpb.ProtoToken
 	st *tokenpb.SignedToken
@@ -231,7 +665,7 @@ func BenchmarkSign(b *testing.B) {
 	}
 }
 
-func setupTest(testName string, keyDataFunc KeyDataFunc) (*setupData, error) {
+func setupTest(testName string, keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*setupData, error) {
 	if keyDataFunc == nil {
 		keydata := make([]byte, 32)
 		_, err := io.ReadFull(rand.Reader, keydata)
@@ -243,7 +677,7 @@ func setupTest(testName string, keyDataFunc KeyDataFunc) (*setupData, error) {
 		}
 	}
 
-	m, err := New(keyDataFunc)
+	m, err := New(keyDataFunc, opts...)
 	if err != nil {
 		return nil, err
 	}
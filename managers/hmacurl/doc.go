@@ -0,0 +1,3 @@
+// Package hmacurl implements [prototokens.TokenManager] via HMAC-SHA256/HMAC-SHA512 signatures and url-safe encoded strings
+// it is intended for services where the issuer and verifier are the same process or share a secret
+package hmacurl
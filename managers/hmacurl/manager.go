@@ -0,0 +1,152 @@
+package hmacurl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Algorithm is the hash used for the HMAC
+type Algorithm int
+
+const (
+	// SHA256 signs with HMAC-SHA256 and requires a key of at least 32 bytes
+	SHA256 Algorithm = iota
+	// SHA512 signs with HMAC-SHA512 and requires a key of at least 64 bytes
+	SHA512
+)
+
+// Manager is an implementation of [prototokens.TokenManager] that:
+// - signs tokens with HMAC with [KeyDataFunc] returning the shared secret
+// - compares signatures in constant time
+// - encodes/decodes with the configured [prototokens.Encoder] (url-safe base64 by default)
+// - checks revocation with an optional [prototokens.RevocationStorer]
+type Manager struct {
+	*prototokens.Validator
+	keyDataFunc KeyDataFunc
+	hash        func() hash.Hash
+	minKeySize  int
+	cfg         *prototokens.ManagerConfig
+}
+
+// KeyDataFunc is a func that can return the shared secret used for the HMAC
+type KeyDataFunc func(context.Context) []byte
+
+// New returns a new [hmacurl.Manager]
+func New(alg Algorithm, keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	m := &Manager{keyDataFunc: keyDataFunc}
+	switch alg {
+	case SHA256:
+		m.hash, m.minKeySize = sha256.New, sha256.Size
+	case SHA512:
+		m.hash, m.minKeySize = sha512.New, sha512.Size
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %d", prototokens.ErrKeyData, alg)
+	}
+	if keyDataFunc == nil {
+		return nil, fmt.Errorf("%w: key data func cannot be nil", prototokens.ErrKeyData)
+	}
+	// get the key and make sure it's valid
+	if _, err := m.key(context.Background()); err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.cfg = cfg
+	m.Validator = prototokens.NewValidator(cfg, m.open)
+	return m, nil
+}
+
+// Sign signs the token
+func (hm *Manager) Sign(ctx context.Context, pt *tokenpb.ProtoToken) (*tokenpb.SignedToken, error) {
+	ctx, span := internal.StartSpan(ctx, "Sign")
+	defer span.End()
+	span.AddEvent("marshal start")
+	b, err := proto.Marshal(pt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	span.AddEvent("marshal end")
+
+	sig, err := hm.sign(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrSign, err)
+	}
+	st := &tokenpb.SignedToken{
+		Signature:  sig,
+		Prototoken: b,
+	}
+	return st, nil
+}
+
+// RevokeToken revokes a token via the configured [prototokens.RevocationStorer]
+// the revocation id is determined by [prototokens.RevocationID]
+func (hm *Manager) RevokeToken(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeToken")
+	defer span.End()
+	return hm.cfg.Revoke(ctx, pt)
+}
+
+// Encode encodes a signed token with the configured [prototokens.Encoder]
+func (hm *Manager) Encode(ctx context.Context, st *tokenpb.SignedToken) (string, error) {
+	_, span := internal.StartSpan(ctx, "Encode")
+	defer span.End()
+	return hm.cfg.Encode(st)
+}
+
+// Decode decodes a signed token with the configured [prototokens.Encoder]
+func (hm *Manager) Decode(ctx context.Context, s string) (*tokenpb.SignedToken, error) {
+	_, span := internal.StartSpan(ctx, "Decode")
+	defer span.End()
+	return hm.cfg.Decode(s)
+}
+
+// key returns the shared secret after making sure it's long enough
+func (hm *Manager) key(ctx context.Context) ([]byte, error) {
+	key := hm.keyDataFunc(ctx)
+	if len(key) < hm.minKeySize {
+		return nil, fmt.Errorf("%w: key too short (want at least: %d have: %d)", prototokens.ErrKeyData, hm.minKeySize, len(key))
+	}
+	return key, nil
+}
+
+func (hm *Manager) sign(ctx context.Context, data []byte) ([]byte, error) {
+	key, err := hm.key(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(hm.hash, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// open verifies the signature over the raw token bytes returning them once they can be trusted
+func (hm *Manager) open(ctx context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	if err := hm.verify(ctx, st.GetSignature(), st.GetPrototoken()); err != nil {
+		return nil, err
+	}
+	return st.GetPrototoken(), nil
+}
+
+func (hm *Manager) verify(ctx context.Context, sig []byte, data []byte) error {
+	expected, err := hm.sign(ctx, data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, sig) {
+		return prototokens.ErrTamper
+	}
+	return nil
+}
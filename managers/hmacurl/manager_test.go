@@ -0,0 +1,169 @@
+package hmacurl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.TokenManager)(nil), &Manager{}, "should implement the interface")
+}
+
+func TestHappyPath(t *testing.T) {
+	for name, alg := range map[string]Algorithm{"sha256": SHA256, "sha512": SHA512} {
+		t.Run(name, func(t *testing.T) {
+			data, err := setupTest(t.Name(), alg, nil)
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			enc, err := data.m.Encode(ctx, data.st)
+			require.NoError(t, err, "should encode")
+			dec, err := data.m.Decode(ctx, enc)
+			require.NoError(t, err, "should decode")
+			require.True(t, proto.Equal(data.st, dec), "original and decoded signed token should be the same")
+
+			vt, err := data.m.GetValidatedToken(ctx, dec)
+			require.NoError(t, err, "should not error")
+			require.True(t, proto.Equal(data.pt, vt), "original and validated token should be the same")
+			require.NoError(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+			require.ErrorIs(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE), prototokens.ErrNotValidForUsage)
+
+			pt, err := data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), prototokens.RequireSID(data.pt.GetSid()))
+			require.NoError(t, err, "should validate and parse in one pass")
+			require.True(t, proto.Equal(data.pt, pt), "original and parsed token should be the same")
+			_, err = data.m.ValidateAndParse(ctx, enc, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_MACHINE))
+			require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	data, err := setupTest(t.Name(), SHA256, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("tamper", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Sid = "mismatch"
+		b, err := proto.Marshal(cloned)
+		require.NoError(t, err)
+		st := proto.Clone(data.st).(*tokenpb.SignedToken)
+		st.Prototoken = b
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrTamper)
+	})
+	t.Run("other-key", func(t *testing.T) {
+		other, err := setupTest(t.Name(), SHA256, nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, other.st), prototokens.ErrTamper)
+	})
+	t.Run("not-yet-valid", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Timestamps.NotValidBefore = timestamppb.New(time.Now().Add(5 * time.Minute))
+		st, err := data.m.Sign(ctx, cloned)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNotYetValid)
+	})
+	t.Run("no-longer-valid", func(t *testing.T) {
+		cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+		cloned.Timestamps.NotValidAfter = timestamppb.New(time.Now().Add(-5 * time.Minute))
+		st, err := data.m.Sign(ctx, cloned)
+		require.NoError(t, err)
+		require.ErrorIs(t, data.m.Validate(ctx, st), prototokens.ErrNoLongerValid)
+	})
+	t.Run("invalid-token", func(t *testing.T) {
+		key := make([]byte, 32)
+		m, err := New(SHA256, func(_ context.Context) []byte { return key })
+		require.NoError(t, err)
+		st := &tokenpb.SignedToken{Prototoken: []byte("[]")}
+		// unauthenticated bytes are rejected before they are parsed
+		err = m.Validate(ctx, st)
+		require.ErrorIs(t, err, prototokens.ErrTamper)
+		require.NotErrorIs(t, err, prototokens.ErrUnmarshal)
+
+		// authenticated garbage still fails to unmarshal
+		mac := hmac.New(sha256.New, key)
+		mac.Write(st.Prototoken)
+		st.Signature = mac.Sum(nil)
+		require.ErrorIs(t, m.Validate(ctx, st), prototokens.ErrUnmarshal)
+	})
+}
+
+func TestKeyData(t *testing.T) {
+	short := func(_ context.Context) []byte { return make([]byte, 32) }
+	_, err := New(SHA256, short)
+	require.NoError(t, err, "32 bytes should be enough for sha256")
+	_, err = New(SHA512, short)
+	require.ErrorIs(t, err, prototokens.ErrKeyData, "32 bytes should not be enough for sha512")
+	_, err = New(SHA256, nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+	_, err = New(Algorithm(99), short)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+
+	// keys are checked on every call
+	key := make([]byte, 32)
+	m, err := New(SHA256, func(_ context.Context) []byte { return key })
+	require.NoError(t, err)
+	key = key[:16]
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	_, err = m.Sign(context.Background(), pt)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+type setupData struct {
+	pt *tokenpb.ProtoToken
+	st *tokenpb.SignedToken
+	m  prototokens.TokenManager
+}
+
+func setupTest(testName string, alg Algorithm, keyDataFunc KeyDataFunc, opts ...prototokens.ManagerOpt) (*setupData, error) {
+	if keyDataFunc == nil {
+		keydata := make([]byte, 64)
+		_, err := io.ReadFull(rand.Reader, keydata)
+		if err != nil {
+			return nil, err
+		}
+		keyDataFunc = func(_ context.Context) []byte {
+			return keydata
+		}
+	}
+
+	m, err := New(alg, keyDataFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := prototokens.New(
+		1*time.Hour,
+		prototokens.WithID(testName+"_id"),
+		prototokens.WithSID(testName+"_sid"),
+		prototokens.WithVendor([]byte(testName+"_vendor")),
+		prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := m.Sign(context.TODO(), pt)
+	if err != nil {
+		return nil, err
+	}
+	return &setupData{
+		pt: pt,
+		st: st,
+		m:  m,
+	}, nil
+}
@@ -0,0 +1,3 @@
+// Package signerurl implements [prototokens.TokenManager] via any [crypto.Signer] and url-safe encoded strings
+// it allows signing with keys that never leave an HSM, TPM or remote signing service
+package signerurl
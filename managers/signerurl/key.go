@@ -0,0 +1,66 @@
+package signerurl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/lusis/prototokens"
+)
+
+// hashFor returns the hash used for signatures with the provided public key
+// ed25519 signs the message directly so it returns crypto.Hash(0)
+func hashFor(pub crypto.PublicKey) (crypto.Hash, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return 0, fmt.Errorf("%w: invalid public key size (want: %d have: %d)", prototokens.ErrKeyData, ed25519.PublicKeySize, len(k))
+		}
+		return crypto.Hash(0), nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return crypto.SHA256, nil
+		case elliptic.P384():
+			return crypto.SHA384, nil
+		case elliptic.P521():
+			return crypto.SHA512, nil
+		default:
+			return 0, fmt.Errorf("%w: unsupported curve %s", prototokens.ErrKeyData, k.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		if k.Size() < 256 {
+			return 0, fmt.Errorf("%w: rsa keys must be at least 2048 bits (have: %d)", prototokens.ErrKeyData, k.Size()*8)
+		}
+		return crypto.SHA256, nil
+	default:
+		return 0, fmt.Errorf("%w: unsupported public key type %T", prototokens.ErrKeyData, pub)
+	}
+}
+
+// digest hashes the data if the hash requires it
+func digest(h crypto.Hash, data []byte) []byte {
+	if h == crypto.Hash(0) {
+		return data
+	}
+	hh := h.New()
+	_, _ = hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// verifySignature verifies a signature created by a [crypto.Signer] for the public key
+func verifySignature(pub crypto.PublicKey, h crypto.Hash, data, sig []byte) bool {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest(h, data), sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, h, digest(h, data), sig) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+package signerurl
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Manager is an implementation of [prototokens.TokenManager] that:
+// - signs tokens with a [crypto.Signer] so key material never needs to be in process memory
+// - verifies with the signer's [crypto.PublicKey] or a provided one via [NewVerifier]
+// - supports ed25519, ECDSA (P-256/SHA-256, P-384/SHA-384, P-521/SHA-512) and RSA (PKCS#1 v1.5/SHA-256) keys
+// - encodes/decodes with the configured [prototokens.Encoder] (url-safe base64 by default)
+// - checks revocation with an optional [prototokens.RevocationStorer]
+type Manager struct {
+	*prototokens.Validator
+	signer crypto.Signer
+	pub    crypto.PublicKey
+	hash   crypto.Hash
+	cfg    *prototokens.ManagerConfig
+}
+
+// New returns a new [signerurl.Manager] that signs with the [crypto.Signer] and verifies with its public key
+func New(signer crypto.Signer, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("%w: signer cannot be nil", prototokens.ErrKeyData)
+	}
+	m, err := newManager(signer.Public(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.signer = signer
+	return m, nil
+}
+
+// NewVerifier returns a new [signerurl.Manager] that can only verify tokens
+// Sign returns [prototokens.ErrVerifyOnly] while every other method works normally
+func NewVerifier(pub crypto.PublicKey, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	return newManager(pub, opts...)
+}
+
+func newManager(pub crypto.PublicKey, opts ...prototokens.ManagerOpt) (*Manager, error) {
+	h, err := hashFor(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := prototokens.NewManagerConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{pub: pub, hash: h, cfg: cfg}
+	m.Validator = prototokens.NewValidator(cfg, m.open)
+	return m, nil
+}
+
+// Sign signs the token
+func (sm *Manager) Sign(ctx context.Context, pt *tokenpb.ProtoToken) (*tokenpb.SignedToken, error) {
+	ctx, span := internal.StartSpan(ctx, "Sign")
+	defer span.End()
+	span.AddEvent("marshal start")
+	b, err := proto.Marshal(pt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	span.AddEvent("marshal end")
+
+	sig, err := sm.sign(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrSign, err)
+	}
+	st := &tokenpb.SignedToken{
+		Signature:  sig,
+		Prototoken: b,
+	}
+	return st, nil
+}
+
+// RevokeToken revokes a token via the configured [prototokens.RevocationStorer]
+// the revocation id is determined by [prototokens.RevocationID]
+func (sm *Manager) RevokeToken(ctx context.Context, pt *tokenpb.ProtoToken) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeToken")
+	defer span.End()
+	return sm.cfg.Revoke(ctx, pt)
+}
+
+// Encode encodes a signed token with the configured [prototokens.Encoder]
+func (sm *Manager) Encode(ctx context.Context, st *tokenpb.SignedToken) (string, error) {
+	_, span := internal.StartSpan(ctx, "Encode")
+	defer span.End()
+	return sm.cfg.Encode(st)
+}
+
+// Decode decodes a signed token with the configured [prototokens.Encoder]
+func (sm *Manager) Decode(ctx context.Context, s string) (*tokenpb.SignedToken, error) {
+	_, span := internal.StartSpan(ctx, "Decode")
+	defer span.End()
+	return sm.cfg.Decode(s)
+}
+
+func (sm *Manager) sign(ctx context.Context, data []byte) ([]byte, error) {
+	if sm.signer == nil {
+		return nil, prototokens.ErrVerifyOnly
+	}
+	_, span := internal.StartSpan(ctx, "crypto.Signer.Sign")
+	defer span.End()
+	return sm.signer.Sign(rand.Reader, digest(sm.hash, data), sm.hash)
+}
+
+// open verifies the signature over the raw token bytes returning them once they can be trusted
+func (sm *Manager) open(ctx context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	if err := sm.verify(ctx, st.GetSignature(), st.GetPrototoken()); err != nil {
+		return nil, err
+	}
+	return st.GetPrototoken(), nil
+}
+
+func (sm *Manager) verify(_ context.Context, sig []byte, data []byte) error {
+	if verified := verifySignature(sm.pub, sm.hash, data, sig); !verified {
+		return prototokens.ErrTamper
+	}
+	return nil
+}
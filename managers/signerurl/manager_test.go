@@ -0,0 +1,165 @@
+package signerurl
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner stands in for an HSM or remote signer
+// the manager only ever sees the public key and the Sign method
+type fakeSigner struct {
+	signer crypto.Signer
+	calls  int
+	err    error
+}
+
+func (fs *fakeSigner) Public() crypto.PublicKey {
+	return fs.signer.Public()
+}
+
+func (fs *fakeSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	fs.calls++
+	if fs.err != nil {
+		return nil, fs.err
+	}
+	return fs.signer.Sign(r, digest, opts)
+}
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.TokenManager)(nil), &Manager{}, "should implement the interface")
+}
+
+func TestKeyTypes(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	testCases := map[string]crypto.Signer{
+		"ed25519": edPriv,
+		"p256":    p256,
+		"p384":    p384,
+		"rsa":     rsaPriv,
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			fs := &fakeSigner{signer: tc}
+			data, err := setupTest(t.Name(), fs)
+			require.NoError(t, err)
+			require.Equal(t, 1, fs.calls, "should sign with the signer")
+			ctx := context.Background()
+
+			enc, err := data.m.Encode(ctx, data.st)
+			require.NoError(t, err, "should encode")
+			dec, err := data.m.Decode(ctx, enc)
+			require.NoError(t, err, "should decode")
+			vt, err := data.m.GetValidatedToken(ctx, dec)
+			require.NoError(t, err, "should not error")
+			require.True(t, proto.Equal(data.pt, vt), "original and validated token should be the same")
+			require.NoError(t, data.m.ValidFor(ctx, dec, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+
+			cloned := proto.Clone(data.pt).(*tokenpb.ProtoToken)
+			cloned.Sid = "mismatch"
+			b, err := proto.Marshal(cloned)
+			require.NoError(t, err)
+			tampered := proto.Clone(data.st).(*tokenpb.SignedToken)
+			tampered.Prototoken = b
+			require.ErrorIs(t, data.m.Validate(ctx, tampered), prototokens.ErrTamper)
+
+			verifier, err := NewVerifier(tc.Public())
+			require.NoError(t, err)
+			require.NoError(t, verifier.Validate(ctx, data.st))
+			_, err = verifier.Sign(ctx, data.pt)
+			require.ErrorIs(t, err, prototokens.ErrVerifyOnly)
+		})
+	}
+}
+
+func TestSignerErrors(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	fs := &fakeSigner{signer: p256, err: fmt.Errorf("hsm unavailable")}
+	m, err := New(fs)
+	require.NoError(t, err)
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	_, err = m.Sign(context.Background(), pt)
+	require.ErrorIs(t, err, prototokens.ErrSign)
+}
+
+func TestUnsupportedKeys(t *testing.T) {
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	require.NoError(t, err)
+	smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	testCases := map[string]crypto.PublicKey{
+		"nil":           nil,
+		"p224":          &p224.PublicKey,
+		"small-rsa":     &smallRSA.PublicKey,
+		"short-ed25519": ed25519.PublicKey([]byte("short")),
+		"bytes":         []byte("snarf"),
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := NewVerifier(tc)
+			require.ErrorIs(t, err, prototokens.ErrKeyData)
+		})
+	}
+	_, err = New(nil)
+	require.ErrorIs(t, err, prototokens.ErrKeyData)
+}
+
+type setupData struct {
+	pt *tokenpb.ProtoToken
+	st *tokenpb.SignedToken
+	m  prototokens.TokenManager
+}
+
+func setupTest(testName string, signer crypto.Signer, opts ...prototokens.ManagerOpt) (*setupData, error) {
+	m, err := New(signer, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := prototokens.New(
+		1*time.Hour,
+		prototokens.WithID(testName+"_id"),
+		prototokens.WithSID(testName+"_sid"),
+		prototokens.WithVendor([]byte(testName+"_vendor")),
+		prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := m.Sign(context.TODO(), pt)
+	if err != nil {
+		return nil, err
+	}
+	return &setupData{
+		pt: pt,
+		st: st,
+		m:  m,
+	}, nil
+}
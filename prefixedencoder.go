@@ -0,0 +1,69 @@
+package prototokens
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strings"
+)
+
+// checksumLength is the length of a base62 encoded crc32 (62^6 > 2^32)
+const checksumLength = 6
+
+var prefixPattern = regexp.MustCompile(`^[A-Za-z0-9]+(_[A-Za-z0-9]+)*$`)
+
+// PrefixedEncoder is an [Encoder] that produces strings in the form `<prefix>_<payload>_<checksum>`
+// similar to GitHub or Stripe api keys so that secret scanners can detect them
+// - the payload is encoded with the wrapped [Encoder] ([Base62Encoder] by default)
+// - the checksum is a crc32 of everything before it, encoded as fixed width base62
+// DecodeString checks the prefix and checksum before decoding the payload so typos are rejected before any crypto runs
+// and returns [ErrPrefix] or [ErrChecksum] respectively
+type PrefixedEncoder struct {
+	prefix  string
+	payload Encoder
+}
+
+// NewPrefixedEncoder returns a new [PrefixedEncoder]
+// prefix is something like `myco_live` and may only contain ascii letters and digits separated by single underscores
+// payload is the [Encoder] used for the payload and defaults to [Base62Encoder] if nil
+func NewPrefixedEncoder(prefix string, payload Encoder) (*PrefixedEncoder, error) {
+	if !prefixPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("%w: invalid prefix %q", ErrPrefix, prefix)
+	}
+	if payload == nil {
+		payload = Base62Encoder
+	}
+	return &PrefixedEncoder{prefix: prefix + "_", payload: payload}, nil
+}
+
+// EncodeToString encodes the bytes with the prefix and checksum
+func (pe *PrefixedEncoder) EncodeToString(b []byte) string {
+	body := pe.prefix + pe.payload.EncodeToString(b)
+	return body + "_" + checksum(body)
+}
+
+// DecodeString checks the prefix and checksum and decodes the payload
+func (pe *PrefixedEncoder) DecodeString(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, pe.prefix) {
+		return nil, fmt.Errorf("%w: expected prefix %q", ErrPrefix, pe.prefix)
+	}
+	sep := len(s) - checksumLength - 1
+	if sep < len(pe.prefix) || s[sep] != '_' {
+		return nil, fmt.Errorf("%w: missing checksum", ErrChecksum)
+	}
+	body, sum := s[:sep], s[sep+1:]
+	if checksum(body) != sum {
+		return nil, ErrChecksum
+	}
+	return pe.payload.DecodeString(body[len(pe.prefix):])
+}
+
+// checksum returns the crc32 of s as fixed width base62
+func checksum(s string) string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, crc32.ChecksumIEEE([]byte(s)))
+	sum := Base62Encoder.EncodeToString(b)
+	// pad to a fixed width so the checksum can always be located
+	return strings.Repeat("0", checksumLength-len(sum)) + sum
+}
@@ -0,0 +1,59 @@
+package prototokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixedEncoder(t *testing.T) {
+	pe, err := NewPrefixedEncoder("myco_live", nil)
+	require.NoError(t, err)
+	in := []byte("some token bytes")
+
+	enc := pe.EncodeToString(in)
+	require.True(t, strings.HasPrefix(enc, "myco_live_"), "should start with the prefix")
+	require.Regexp(t, `^myco_live_[0-9A-Za-z]+_[0-9A-Za-z]{6}$`, enc)
+	dec, err := pe.DecodeString(enc)
+	require.NoError(t, err)
+	require.Equal(t, in, dec)
+
+	t.Run("wrong-prefix", func(t *testing.T) {
+		_, err := pe.DecodeString(strings.Replace(enc, "live", "test", 1))
+		require.ErrorIs(t, err, ErrPrefix)
+	})
+	t.Run("typo", func(t *testing.T) {
+		b := []byte(enc)
+		i := len("myco_live_") + 2
+		if b[i] == 'a' {
+			b[i] = 'b'
+		} else {
+			b[i] = 'a'
+		}
+		_, err := pe.DecodeString(string(b))
+		require.ErrorIs(t, err, ErrChecksum)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		_, err := pe.DecodeString(enc[:len(enc)-1])
+		require.ErrorIs(t, err, ErrChecksum)
+		_, err = pe.DecodeString("myco_live_")
+		require.ErrorIs(t, err, ErrChecksum)
+	})
+	t.Run("underscores-in-payload", func(t *testing.T) {
+		pe, err := NewPrefixedEncoder("myco", Base64URLEncoder)
+		require.NoError(t, err)
+		in := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+		enc := pe.EncodeToString(in)
+		require.Contains(t, strings.TrimPrefix(enc, "myco_"), "_")
+		dec, err := pe.DecodeString(enc)
+		require.NoError(t, err)
+		require.Equal(t, in, dec)
+	})
+	t.Run("invalid-prefix", func(t *testing.T) {
+		for _, prefix := range []string{"", "_myco", "myco_", "my-co", "myco__live"} {
+			_, err := NewPrefixedEncoder(prefix, nil)
+			require.ErrorIs(t, err, ErrPrefix, prefix)
+		}
+	})
+}
@@ -12,27 +12,41 @@ import (
 )
 
 // New returns a new prototoken valid for the provided duration
+// the token is issued and becomes valid now unless [WithNotValidBefore] or [WithValidityWindow] are provided
+// timestamps are only filled in after every option has been applied so options can tell what has been set explicitly
 func New(duration time.Duration, opts ...TokenOpt) (*tokenpb.ProtoToken, error) {
 	if duration == 0 {
 		// no you can't shoot yourself in the foot sorry
 		// provide a really long duration if you want a long-lived token
 		panic("duration MUST be provided")
 	}
-	nvb := time.Now().UTC()
-	nva := nvb.Add(duration)
-	tok := &tokenpb.ProtoToken{
-		Timestamps: &tokenpb.Timestamps{
-			NotValidBefore: timestamppb.New(nvb),
-			NotValidAfter:  timestamppb.New(nva),
-		},
-	}
+	tok := &tokenpb.ProtoToken{Timestamps: &tokenpb.Timestamps{}}
 	for _, opt := range opts {
 		if err := opt(tok); err != nil {
 			return nil, err
 		}
 	}
+	ts := tok.GetTimestamps()
+	if ts.GetIssuedAt() == nil {
+		ts.IssuedAt = timestamppb.New(now(SystemClock))
+	}
+	if ts.GetNotValidBefore() == nil {
+		ts.NotValidBefore = timestamppb.New(ts.GetIssuedAt().AsTime())
+	}
+	if ts.GetNotValidAfter() == nil {
+		ts.NotValidAfter = timestamppb.New(ts.GetNotValidBefore().AsTime().Add(duration))
+	}
 	if tok.GetId() == "" {
 		tok.Id = ksuid.New().String()
 	}
 	return tok, nil
 }
+
+// IssuedAt returns when the token was issued
+// tokens minted before issued_at existed fall back to NotValidBefore which was always the issue time
+func IssuedAt(tok *tokenpb.ProtoToken) time.Time {
+	if ia := tok.GetTimestamps().GetIssuedAt(); ia != nil {
+		return ia.AsTime().UTC()
+	}
+	return tok.GetTimestamps().GetNotValidBefore().AsTime().UTC()
+}
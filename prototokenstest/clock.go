@@ -0,0 +1,39 @@
+package prototokenstest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a [prototokens.Clock] that only moves when told to
+// it is safe for concurrent use
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock returns a new [FakeClock] set to the provided time
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the current fake time
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	return fc.now
+}
+
+// Advance moves the clock forward by the provided duration. negative durations move it backwards
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+}
+
+// Set sets the clock to the provided time
+func (fc *FakeClock) Set(t time.Time) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = t
+}
@@ -0,0 +1,21 @@
+package prototokenstest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	require.Implements(t, (*prototokens.Clock)(nil), &FakeClock{}, "should implement the interface")
+	start := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	fc := NewFakeClock(start)
+	require.Equal(t, start, fc.Now())
+	fc.Advance(time.Hour)
+	require.Equal(t, start.Add(time.Hour), fc.Now())
+	fc.Set(start)
+	require.Equal(t, start, fc.Now())
+}
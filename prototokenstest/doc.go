@@ -0,0 +1,2 @@
+// Package prototokenstest provides helpers for testing code that uses prototokens
+package prototokenstest
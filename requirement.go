@@ -0,0 +1,81 @@
+package prototokens
+
+import (
+	"fmt"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+)
+
+// Requirement is a check applied to a [tokenpb.ProtoToken] after its signature, timestamps and revocation have been validated
+// requirements should return a [ValidationError] so callers get a machine-readable reason
+type Requirement func(*tokenpb.ProtoToken) error
+
+// RequireUsages requires the token to be valid for every provided usage
+func RequireUsages(usages ...tokenpb.TokenUsages) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		return CheckUsages(tok, usages...)
+	}
+}
+
+// RequireAnyUsage requires the token to be valid for at least one of the provided usages
+func RequireAnyUsage(usages ...tokenpb.TokenUsages) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		return CheckAnyUsage(tok, usages...)
+	}
+}
+
+// RequireSID requires the token to have the provided sid
+func RequireSID(sid string) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		if tok.GetSid() != sid {
+			return tokenError(tok, ReasonRequirement, 0, fmt.Errorf("%w: sid %q does not match %q", ErrRequirement, tok.GetSid(), sid))
+		}
+		return nil
+	}
+}
+
+// RequireIssuer requires the token to have been minted by the provided issuer
+func RequireIssuer(issuer string) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		if tok.GetIssuer() != issuer {
+			return tokenError(tok, ReasonClaim, 0, fmt.Errorf("%w: issuer %q does not match %q", ErrClaimMismatch, tok.GetIssuer(), issuer))
+		}
+		return nil
+	}
+}
+
+// RequireAudience requires the provided audience to be one of the token's audiences
+func RequireAudience(audience string) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		for _, a := range tok.GetAudience() {
+			if a == audience {
+				return nil
+			}
+		}
+		return tokenError(tok, ReasonClaim, 0, fmt.Errorf("%w: audience %q not in %q", ErrClaimMismatch, audience, tok.GetAudience()))
+	}
+}
+
+// RequireSubject requires the token to have the provided subject
+func RequireSubject(subject string) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		if tok.GetSubject() != subject {
+			return tokenError(tok, ReasonClaim, 0, fmt.Errorf("%w: subject %q does not match %q", ErrClaimMismatch, tok.GetSubject(), subject))
+		}
+		return nil
+	}
+}
+
+// CheckRequirements checks every [Requirement] against a token whose signature has already been verified
+// it returns the first failure
+func CheckRequirements(tok *tokenpb.ProtoToken, reqs ...Requirement) error {
+	for _, req := range reqs {
+		if req == nil {
+			continue
+		}
+		if err := req(tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package prototokens
+
+import (
+	"testing"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirements(t *testing.T) {
+	tok, err := New(time.Hour,
+		WithID(t.Name()),
+		WithSID(t.Name()+"_sid"),
+		WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE),
+		WithIssuer("billing"),
+		WithAudience("billing", "admin"),
+		WithSubject("user:1"),
+	)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		reqs   []Requirement
+		is     error
+		reason ValidationReason
+	}{
+		"none": {},
+		"nil":  {reqs: []Requirement{nil}},
+		"usages": {
+			reqs: []Requirement{RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE)},
+		},
+		"missing-usage": {
+			reqs:   []Requirement{RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE)},
+			is:     ErrNotValidForUsage,
+			reason: ReasonUsage,
+		},
+		"sid": {
+			reqs: []Requirement{RequireSID(t.Name() + "_sid")},
+		},
+		"claims": {
+			reqs: []Requirement{RequireIssuer("billing"), RequireAudience("admin"), RequireSubject("user:1")},
+		},
+		"wrong-issuer": {
+			reqs:   []Requirement{RequireIssuer("support")},
+			is:     ErrClaimMismatch,
+			reason: ReasonClaim,
+		},
+		"wrong-audience": {
+			reqs:   []Requirement{RequireAudience("support")},
+			is:     ErrClaimMismatch,
+			reason: ReasonClaim,
+		},
+		"wrong-subject": {
+			reqs:   []Requirement{RequireSubject("user:2")},
+			is:     ErrClaimMismatch,
+			reason: ReasonClaim,
+		},
+		"wrong-sid": {
+			reqs:   []Requirement{RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), RequireSID("other")},
+			is:     ErrRequirement,
+			reason: ReasonRequirement,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			err := CheckRequirements(tok, tc.reqs...)
+			if tc.is == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.is)
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			require.Equal(t, tc.reason, ve.Reason)
+			require.Equal(t, tok.GetId(), ve.ID)
+		})
+	}
+}
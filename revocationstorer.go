@@ -2,6 +2,9 @@ package prototokens
 
 import (
 	"context"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
 )
 
 // RevocationStorer is an interface for storing tokens that have been revoked
@@ -11,9 +14,52 @@ type RevocationStorer interface {
 	// you can just use the signature if you want
 	Revoke(ctx context.Context, revocationID string) error
 	// CheckRevocation checks the store to see if the token has been revoked
+	// implementations should return [ErrTokenRevoked] if the token has been revoked
+	// and nil if it has not
 	CheckRevocation(ctx context.Context, revocatonID string) error
 }
 
+// ExpiringRevocationStorer is a [RevocationStorer] that can forget about a revocation
+// once the revoked token would no longer be valid anyway
+// [TokenManager] implementations should prefer RevokeUntil when the store supports it
+type ExpiringRevocationStorer interface {
+	RevocationStorer
+	// RevokeUntil revokes a [tokenpb.ProtoToken] by its identifier until the provided time
+	// generally the time is the token's NotValidAfter timestamp plus any leeway the verifier allows
+	RevokeUntil(ctx context.Context, revocationID string, until time.Time) error
+}
+
+// BulkRevocationStorer is a [RevocationStorer] that can revoke many tokens at once
+// by their sid or by when they were issued
+// [TokenManager] implementations check it in addition to [RevocationStorer.CheckRevocation] when the store supports it
+type BulkRevocationStorer interface {
+	RevocationStorer
+	// RevokeSID permanently bans the sid revoking every [tokenpb.ProtoToken] with it including tokens issued in the future
+	// it cannot be undone. to log a sid out use [BulkRevocationStorer.RevokeIssuedBefore] with the current time instead
+	RevokeSID(ctx context.Context, sid string) error
+	// RevokeIssuedBefore revokes every [tokenpb.ProtoToken] with the provided sid issued before the cutoff
+	// an empty sid revokes every token issued before the cutoff
+	// the cutoff is compared exactly with the token's issued_at so tokens issued right after it stay valid
+	RevokeIssuedBefore(ctx context.Context, sid string, cutoff time.Time) error
+	// CheckBulkRevocation checks if a token with the provided sid issued at the provided time
+	// has been revoked by [BulkRevocationStorer.RevokeSID] or [BulkRevocationStorer.RevokeIssuedBefore]
+	// implementations should return [ErrTokenRevoked] if the token has been revoked
+	// and nil if it has not
+	CheckBulkRevocation(ctx context.Context, sid string, issuedAt time.Time) error
+}
+
+// RevocationID returns the identifier used by the shipped [TokenManager] implementations
+// when revoking a token or checking if a token has been revoked which is the token's id
+// tokens without an id return [ErrNoRevocationID]. [New] always sets an id so this only affects tokens built by hand
+// there is deliberately no fallback such as a hash of the token: re-marshaling a parsed token isn't canonical
+// across schema versions so an issuer and a verifier could compute different ids and silently miss a revocation
+func RevocationID(pt *tokenpb.ProtoToken) (string, error) {
+	if id := pt.GetId(); id != "" {
+		return id, nil
+	}
+	return "", ErrNoRevocationID
+}
+
 // UnimplementedRevocationStorer is an implementation for testing and compatibility
 type UnimplementedRevocationStorer struct{}
 
@@ -0,0 +1,76 @@
+package prototokens
+
+import (
+	"fmt"
+	"strings"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+)
+
+// ScopeSeparator separates the segments of a hierarchical scope such as repo:read
+const ScopeSeparator = ":"
+
+// ScopeWildcard is a segment in a granted scope that matches any segment of a required scope
+const ScopeWildcard = "*"
+
+// ScopeMatches reports if a granted scope satisfies a required scope
+// - scopes are compared segment by segment
+// - a * segment in the granted scope matches any single segment
+// - a trailing * segment in the granted scope matches one or more remaining segments so repo:* satisfies repo:read and repo:read:meta
+// - a granted scope without a wildcard does not satisfy its children. repo does not satisfy repo:read
+func ScopeMatches(granted, required string) bool {
+	if granted == "" || required == "" {
+		return false
+	}
+	g := strings.Split(granted, ScopeSeparator)
+	r := strings.Split(required, ScopeSeparator)
+	for i, seg := range g {
+		if i >= len(r) {
+			return false
+		}
+		if seg == ScopeWildcard {
+			if i == len(g)-1 {
+				return true
+			}
+			continue
+		}
+		if seg != r[i] {
+			return false
+		}
+	}
+	return len(g) == len(r)
+}
+
+// CheckScopes returns [ErrNotValidForScopes] listing every required scope the token's scopes don't satisfy
+// providing no scopes always fails. the error is a [ValidationError] with [ReasonScope]
+func CheckScopes(tok *tokenpb.ProtoToken, scopes ...string) error {
+	if len(scopes) == 0 {
+		return tokenError(tok, ReasonScope, 0, fmt.Errorf("%w: no scopes provided", ErrNotValidForScopes))
+	}
+	var missing []string
+	for _, required := range scopes {
+		if !hasScope(tok.GetScopes(), required) {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return tokenError(tok, ReasonScope, 0, fmt.Errorf("%w: missing %s", ErrNotValidForScopes, strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// RequireScopes requires the token's scopes to satisfy every provided scope via [ScopeMatches]
+func RequireScopes(scopes ...string) Requirement {
+	return func(tok *tokenpb.ProtoToken) error {
+		return CheckScopes(tok, scopes...)
+	}
+}
+
+func hasScope(granted []string, required string) bool {
+	for _, g := range granted {
+		if ScopeMatches(g, required) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package prototokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeMatches(t *testing.T) {
+	testCases := map[string]struct {
+		granted  string
+		required string
+		match    bool
+	}{
+		"exact":                  {granted: "repo:read", required: "repo:read", match: true},
+		"different":              {granted: "repo:read", required: "repo:write"},
+		"trailing-wildcard":      {granted: "repo:*", required: "repo:read", match: true},
+		"trailing-wildcard-deep": {granted: "repo:*", required: "repo:read:meta", match: true},
+		"wildcard-needs-segment": {granted: "repo:*", required: "repo"},
+		"wildcard-other-prefix":  {granted: "repo:*", required: "org:read"},
+		"global-wildcard":        {granted: "*", required: "org:admin", match: true},
+		"middle-wildcard":        {granted: "repo:*:read", required: "repo:prototokens:read", match: true},
+		"middle-wildcard-miss":   {granted: "repo:*:read", required: "repo:prototokens:write"},
+		"middle-wildcard-short":  {granted: "repo:*:read", required: "repo:prototokens"},
+		"parent-not-child":       {granted: "repo", required: "repo:read"},
+		"child-not-parent":       {granted: "repo:read", required: "repo"},
+		"empty-granted":          {granted: "", required: "repo"},
+		"empty-required":         {granted: "*", required: ""},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.match, ScopeMatches(tc.granted, tc.required))
+		})
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	tok, err := New(time.Hour, WithID(t.Name()), WithScopes("repo:*", "org:read"))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, CheckScopes(tok), ErrNotValidForScopes, "no scopes should fail closed")
+	require.NoError(t, CheckScopes(tok, "repo:read", "repo:write", "org:read"))
+	err = CheckScopes(tok, "repo:read", "org:write", "billing:read")
+	require.ErrorIs(t, err, ErrNotValidForScopes)
+	require.ErrorContains(t, err, "missing org:write, billing:read", "should list every missing scope")
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, ReasonScope, ve.Reason)
+	require.Equal(t, tok.GetId(), ve.ID)
+
+	require.ErrorIs(t, CheckRequirements(tok, RequireScopes("org:admin")), ErrNotValidForScopes)
+}
@@ -0,0 +1,3 @@
+// Package filestore implements [prototokens.RevocationStorer] with an append-only log file
+// it provides durable revocation for single-binary deployments without any external services
+package filestore
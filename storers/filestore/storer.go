@@ -0,0 +1,374 @@
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+)
+
+// record is a single line in the log
+// a record with an ID is a token revocation
+// a record without an ID is a sid revocation or cutoff
+type record struct {
+	ID string `json:"id,omitempty"`
+	// Until is the unix time in seconds the revocation expires. zero never expires
+	Until int64  `json:"until,omitempty"`
+	SID   string `json:"sid,omitempty"`
+	// Before is the unix time in nanoseconds tokens for the sid must be issued at or after. zero revokes the sid entirely
+	Before int64 `json:"before,omitempty"`
+}
+
+// Storer is an implementation of [prototokens.ExpiringRevocationStorer] and [prototokens.BulkRevocationStorer] that:
+// - appends each revocation to a log file and fsyncs it before returning
+// - replays the log into an in-memory index on startup
+// - answers [Storer.CheckRevocation] from the in-memory index
+// - drops expired revocations from the log via [Storer.Compact]
+// - rolls back a failed append and refuses later writes if it can't so the log never has a partial line in the middle
+// only a single process should open a given log file at a time
+type Storer struct {
+	mu      sync.RWMutex
+	path    string
+	f       *os.File
+	revoked map[string]int64
+	// cutoffs are keyed by sid with the global cutoff stored under an empty sid in unix nanoseconds
+	// a cutoff of zero revokes every token for the sid
+	cutoffs map[string]int64
+	// broken is set when a failed write could not be rolled back. every later write is refused
+	// since appending after a partial line would leave a corrupt entry in the middle of the log
+	broken error
+}
+
+// New returns a new [filestore.Storer] backed by the log file at path
+// the file is created if it does not exist
+// callers should call [Storer.Close] when done
+func New(path string) (*Storer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	s := &Storer{
+		path:    path,
+		revoked: map[string]int64{},
+		cutoffs: map[string]int64{},
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open revocation log: %w", err)
+	}
+	s.f = f
+	return s, nil
+}
+
+// Revoke revokes a token by its identifier forever
+// prefer [Storer.RevokeUntil] so that revocations can be compacted
+func (s *Storer) Revoke(ctx context.Context, revocationID string) error {
+	return s.RevokeUntil(ctx, revocationID, time.Time{})
+}
+
+// RevokeUntil revokes a token by its identifier until the provided time
+// a zero time means the revocation never expires
+// an existing revocation is never shortened
+func (s *Storer) RevokeUntil(ctx context.Context, revocationID string, until time.Time) error {
+	_, span := internal.StartSpan(ctx, "RevokeUntil")
+	defer span.End()
+	if revocationID == "" {
+		return fmt.Errorf("revocation id cannot be empty")
+	}
+	rec := record{ID: revocationID}
+	if !until.IsZero() {
+		// round up so a revocation is never compacted before the token expires
+		rec.Until = until.Add(time.Second - 1).Unix()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if existing, ok := s.revoked[rec.ID]; ok && !supersedes(existing, rec.Until) {
+		return nil
+	}
+	if err := s.append(rec); err != nil {
+		return err
+	}
+	s.revoked[rec.ID] = rec.Until
+	return nil
+}
+
+// RevokeSID permanently bans the sid revoking every token with it including tokens issued in the future
+// there is no way to undo it. use [Storer.RevokeIssuedBefore] with the current time to log a sid out
+func (s *Storer) RevokeSID(ctx context.Context, sid string) error {
+	_, span := internal.StartSpan(ctx, "RevokeSID")
+	defer span.End()
+	if sid == "" {
+		return fmt.Errorf("sid cannot be empty")
+	}
+	return s.setCutoff(record{SID: sid})
+}
+
+// RevokeIssuedBefore revokes every token with the provided sid issued before the cutoff
+// an empty sid revokes every token issued before the cutoff
+// the cutoff is compared exactly with a token's issued_at and an existing cutoff is never moved backwards
+func (s *Storer) RevokeIssuedBefore(ctx context.Context, sid string, cutoff time.Time) error {
+	_, span := internal.StartSpan(ctx, "RevokeIssuedBefore")
+	defer span.End()
+	if cutoff.IsZero() {
+		return fmt.Errorf("cutoff cannot be empty")
+	}
+	return s.setCutoff(record{SID: sid, Before: cutoff.UnixNano()})
+}
+
+// CheckBulkRevocation returns [prototokens.ErrTokenRevoked] if the sid has been revoked
+// or the token was issued before the cutoff for its sid or the global cutoff
+func (s *Storer) CheckBulkRevocation(ctx context.Context, sid string, issuedAt time.Time) error {
+	_, span := internal.StartSpan(ctx, "CheckBulkRevocation")
+	defer span.End()
+	issued := issuedAt.UnixNano()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sid != "" {
+		if before, ok := s.cutoffs[sid]; ok && (before == 0 || issued < before) {
+			return prototokens.ErrTokenRevoked
+		}
+	}
+	if before, ok := s.cutoffs[""]; ok && issued < before {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+// CheckRevocation returns [prototokens.ErrTokenRevoked] if the token has been revoked
+func (s *Storer) CheckRevocation(ctx context.Context, revocationID string) error {
+	_, span := internal.StartSpan(ctx, "CheckRevocation")
+	defer span.End()
+	s.mu.RLock()
+	_, ok := s.revoked[revocationID]
+	s.mu.RUnlock()
+	if ok {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+// Compact rewrites the log without revocations for tokens that would have expired before the provided time
+// the new log is written to a temporary file and renamed over the existing log so a crash never loses data
+// it returns the number of revocations dropped
+func (s *Storer) Compact(ctx context.Context, before time.Time) (int, error) {
+	_, span := internal.StartSpan(ctx, "Compact")
+	defer span.End()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writable(); err != nil {
+		return 0, err
+	}
+
+	cutoff := before.Unix()
+	keep := make(map[string]int64, len(s.revoked))
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for id, until := range s.revoked {
+		if until != 0 && until < cutoff {
+			continue
+		}
+		keep[id] = until
+		if err := enc.Encode(record{ID: id, Until: until}); err != nil {
+			return 0, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+		}
+	}
+	// cutoffs are never dropped
+	for sid, before := range s.cutoffs {
+		if err := enc.Encode(record{SID: sid, Before: before}); err != nil {
+			return 0, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".compact-*")
+	if err != nil {
+		return 0, fmt.Errorf("unable to create compacted log: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("unable to write compacted log: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("unable to write compacted log: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("unable to sync compacted log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("unable to write compacted log: %w", err)
+	}
+	// open the compacted log before it replaces the old one so a failure here leaves s.f pointing at the live log
+	f, err := os.OpenFile(tmp.Name(), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open compacted log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		_ = f.Close()
+		return 0, fmt.Errorf("unable to replace revocation log: %w", err)
+	}
+	syncDir(filepath.Dir(s.path))
+
+	_ = s.f.Close()
+	s.f = f
+	dropped := len(s.revoked) - len(keep)
+	s.revoked = keep
+	return dropped, nil
+}
+
+// Close closes the log file
+func (s *Storer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// replay loads the log into the in-memory index
+// a partial trailing line from a crash mid-write is discarded and truncated
+func (s *Storer) replay() error {
+	f, err := os.OpenFile(s.path, os.O_RDWR, 0o600)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to open revocation log: %w", err)
+	}
+	defer f.Close() // nolint: errcheck
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for lineno := 1; ; lineno++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				if err := f.Truncate(offset); err != nil {
+					return fmt.Errorf("unable to truncate partial revocation log entry: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read revocation log: %w", err)
+		}
+		offset += int64(len(line))
+		rec := record{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("%w: revocation log line %d: %w", prototokens.ErrUnmarshal, lineno, err)
+		}
+		if rec.ID == "" {
+			if existing, ok := s.cutoffs[rec.SID]; !ok || supersedes(existing, rec.Before) {
+				s.cutoffs[rec.SID] = rec.Before
+			}
+			continue
+		}
+		if existing, ok := s.revoked[rec.ID]; !ok || supersedes(existing, rec.Until) {
+			s.revoked[rec.ID] = rec.Until
+		}
+	}
+}
+
+// setCutoff appends a sid revocation or cutoff to the log
+func (s *Storer) setCutoff(rec record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if existing, ok := s.cutoffs[rec.SID]; ok && !supersedes(existing, rec.Before) {
+		return nil
+	}
+	if err := s.append(rec); err != nil {
+		return err
+	}
+	s.cutoffs[rec.SID] = rec.Before
+	return nil
+}
+
+// append writes a record to the log and syncs it
+// a failed write is truncated back to the end of the last complete record so a partial line never ends up
+// in the middle of the log. if that fails too the store refuses every later write
+// callers must hold the lock
+func (s *Storer) append(rec record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	info, err := s.f.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to write revocation log: %w", err)
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		s.rollback(info.Size())
+		return fmt.Errorf("unable to write revocation log: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		s.rollback(info.Size())
+		return fmt.Errorf("unable to sync revocation log: %w", err)
+	}
+	return nil
+}
+
+// rollback truncates the log back to offset after a failed write
+// callers must hold the lock
+func (s *Storer) rollback(offset int64) {
+	if err := s.f.Truncate(offset); err != nil {
+		s.broken = fmt.Errorf("unable to roll back partial revocation log entry: %w", err)
+		return
+	}
+	if err := s.f.Sync(); err != nil {
+		s.broken = fmt.Errorf("unable to roll back partial revocation log entry: %w", err)
+	}
+}
+
+// writable returns an error if the log can't be appended to
+// callers must hold the lock
+func (s *Storer) writable() error {
+	if s.f == nil {
+		return fmt.Errorf("revocation log is closed")
+	}
+	if s.broken != nil {
+		return fmt.Errorf("revocation log is unusable until reopened: %w", s.broken)
+	}
+	return nil
+}
+
+// supersedes reports if next would revoke more than existing
+// for both expiries and cutoffs, zero means forever so it is never superseded
+func supersedes(existing, next int64) bool {
+	if existing == 0 {
+		return false
+	}
+	return next == 0 || next > existing
+}
+
+// syncDir fsyncs a directory so a rename is durable
+// not all platforms support this so errors are ignored
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,181 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.ExpiringRevocationStorer)(nil), &Storer{}, "should implement the interface")
+	require.Implements(t, (*prototokens.BulkRevocationStorer)(nil), &Storer{}, "should implement the interface")
+}
+
+func TestBulkRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	s, err := New(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.CheckBulkRevocation(ctx, "sid", now))
+	require.NoError(t, s.RevokeSID(ctx, "sid"))
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "sid", now), "should not replace a sid revocation")
+	require.Error(t, s.RevokeSID(ctx, ""), "should not allow empty sids")
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now))
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now.Add(-1*time.Hour)), "should not move the cutoff backwards")
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "", now.Add(-30*time.Minute)))
+	require.Error(t, s.RevokeIssuedBefore(ctx, "", time.Time{}), "should require a cutoff")
+
+	check := func(s *Storer) {
+		require.ErrorIs(t, s.CheckBulkRevocation(ctx, "sid", now.Add(time.Hour)), prototokens.ErrTokenRevoked, "should revoke future tokens for the sid")
+		require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Second)), prototokens.ErrTokenRevoked)
+		require.NoError(t, s.CheckBulkRevocation(ctx, "other", now), "tokens issued at or after the cutoff should be valid")
+		require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Nanosecond)), prototokens.ErrTokenRevoked, "cutoffs should be exact")
+		require.NoError(t, s.CheckBulkRevocation(ctx, "other", now.Add(500*time.Millisecond)), "tokens issued later in the same second should be valid")
+		require.ErrorIs(t, s.CheckBulkRevocation(ctx, "", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply without a sid")
+		require.ErrorIs(t, s.CheckBulkRevocation(ctx, "third", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply to every sid")
+		require.NoError(t, s.CheckBulkRevocation(ctx, "third", now))
+	}
+	check(s)
+
+	// cutoffs should survive replay and compaction
+	_, err = s.Compact(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	check(s)
+	require.NoError(t, s.Close())
+	s, err = New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	check(s)
+}
+
+func TestRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	s, err := New(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, s.CheckRevocation(ctx, t.Name()), "should not be revoked")
+	require.NoError(t, s.Revoke(ctx, t.Name()))
+	require.ErrorIs(t, s.CheckRevocation(ctx, t.Name()), prototokens.ErrTokenRevoked)
+	require.Error(t, s.Revoke(ctx, ""), "should not allow empty ids")
+	require.NoError(t, s.Close())
+	require.Error(t, s.Revoke(ctx, t.Name()+"_closed"), "should not write to a closed log")
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	s, err := New(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.Revoke(ctx, "forever"))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(time.Hour)))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(2*time.Hour)))
+	require.NoError(t, s.Close())
+
+	// simulate a crash mid-write
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"partial"`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	require.ErrorIs(t, s.CheckRevocation(ctx, "forever"), prototokens.ErrTokenRevoked)
+	require.ErrorIs(t, s.CheckRevocation(ctx, "current"), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckRevocation(ctx, "partial"), "partial entries should be discarded")
+	require.Equal(t, now.Add(2*time.Hour).Add(time.Second-1).Unix(), s.revoked["current"], "should keep the longest revocation")
+
+	// and appending after the truncated entry should work
+	require.NoError(t, s.Revoke(ctx, "after"))
+	require.NoError(t, s.Close())
+	s, err = New(path)
+	require.NoError(t, err)
+	require.ErrorIs(t, s.CheckRevocation(ctx, "after"), prototokens.ErrTokenRevoked)
+}
+
+func TestFailedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	s, err := New(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, s.Revoke(ctx, "before"))
+
+	// a partial write is rolled back so the next entry starts on its own line
+	info, err := s.f.Stat()
+	require.NoError(t, err)
+	_, err = s.f.WriteString(`{"id":"partial"`)
+	require.NoError(t, err)
+	s.rollback(info.Size())
+	require.NoError(t, s.broken)
+	require.NoError(t, s.Revoke(ctx, "after"))
+
+	// a write that can't be rolled back makes the store refuse every later write
+	rw := s.f
+	ro, err := os.Open(path)
+	require.NoError(t, err)
+	s.f = ro
+	require.Error(t, s.Revoke(ctx, "failed"))
+	require.Error(t, s.broken)
+	s.f = rw
+	require.ErrorContains(t, s.Revoke(ctx, "refused"), "unusable", "should not append after a failed rollback")
+	require.ErrorContains(t, s.RevokeSID(ctx, "refused"), "unusable")
+	_ = ro.Close()
+	require.NoError(t, s.Close())
+
+	s, err = New(path)
+	require.NoError(t, err, "the log should still replay")
+	t.Cleanup(func() { _ = s.Close() })
+	require.ErrorIs(t, s.CheckRevocation(ctx, "before"), prototokens.ErrTokenRevoked)
+	require.ErrorIs(t, s.CheckRevocation(ctx, "after"), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckRevocation(ctx, "partial"))
+	require.NoError(t, s.CheckRevocation(ctx, "refused"))
+}
+
+func TestCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	require.NoError(t, os.WriteFile(path, []byte("snarf\n"), 0o600))
+	_, err := New(path)
+	require.ErrorIs(t, err, prototokens.ErrUnmarshal)
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.log")
+	s, err := New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RevokeUntil(ctx, "expired", now.Add(-1*time.Hour)))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(time.Hour)))
+	require.NoError(t, s.Revoke(ctx, "forever"))
+	require.NoError(t, s.RevokeUntil(ctx, "forever", now.Add(-1*time.Hour)), "should not shorten")
+
+	dropped, err := s.Compact(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+	require.NoError(t, s.CheckRevocation(ctx, "expired"))
+
+	// writes after compaction should land in the new log
+	require.NoError(t, s.Revoke(ctx, "after"))
+	require.NoError(t, s.Close())
+
+	s, err = New(path)
+	require.NoError(t, err)
+	require.NoError(t, s.CheckRevocation(ctx, "expired"), "compaction should be durable")
+	for _, id := range []string{"current", "forever", "after"} {
+		require.ErrorIs(t, s.CheckRevocation(ctx, id), prototokens.ErrTokenRevoked, id)
+	}
+}
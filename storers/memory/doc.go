@@ -0,0 +1,3 @@
+// Package memory implements [prototokens.RevocationStorer] with an in-memory map
+// revocations are evicted in the background once the revoked token would have expired anyway
+package memory
@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+)
+
+// DefaultEvictionInterval is how often expired revocations are evicted if not otherwise provided
+const DefaultEvictionInterval = 1 * time.Minute
+
+// Storer is an implementation of [prototokens.ExpiringRevocationStorer] and [prototokens.BulkRevocationStorer] that:
+// - keeps revocations in memory so it is only useful for a single node or tests
+// - evicts revocations in the background once the token could no longer pass validation
+// - is safe for concurrent use
+// sid revocations and cutoffs are never evicted
+type Storer struct {
+	mu       sync.RWMutex
+	revoked  map[string]time.Time
+	sids     map[string]struct{}
+	cutoffs  map[string]time.Time
+	interval time.Duration
+	clock    prototokens.Clock
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Opt is an option for configuring a [Storer]
+type Opt func(*Storer) error
+
+// WithEvictionInterval sets how often expired revocations are evicted
+func WithEvictionInterval(d time.Duration) Opt {
+	return func(s *Storer) error {
+		if d <= 0 {
+			return fmt.Errorf("eviction interval must be positive")
+		}
+		s.interval = d
+		return nil
+	}
+}
+
+// WithClock uses the provided [prototokens.Clock] when deciding which revocations have expired
+func WithClock(c prototokens.Clock) Opt {
+	return func(s *Storer) error {
+		if c == nil {
+			return fmt.Errorf("clock cannot be nil")
+		}
+		s.clock = c
+		return nil
+	}
+}
+
+// New returns a new [memory.Storer]
+// callers should call [Storer.Close] when done to stop background eviction
+func New(opts ...Opt) (*Storer, error) {
+	s := &Storer{
+		revoked:  map[string]time.Time{},
+		sids:     map[string]struct{}{},
+		cutoffs:  map[string]time.Time{},
+		interval: DefaultEvictionInterval,
+		clock:    prototokens.SystemClock,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	go s.evictLoop()
+	return s, nil
+}
+
+// Revoke revokes a token by its identifier forever
+// prefer [Storer.RevokeUntil] so that memory stays bounded
+func (s *Storer) Revoke(ctx context.Context, revocationID string) error {
+	return s.RevokeUntil(ctx, revocationID, time.Time{})
+}
+
+// RevokeUntil revokes a token by its identifier until the provided time
+// a zero time means the revocation never expires
+func (s *Storer) RevokeUntil(ctx context.Context, revocationID string, until time.Time) error {
+	_, span := internal.StartSpan(ctx, "RevokeUntil")
+	defer span.End()
+	if revocationID == "" {
+		return fmt.Errorf("revocation id cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// never shorten an existing revocation
+	if existing, ok := s.revoked[revocationID]; ok {
+		if existing.IsZero() || (!until.IsZero() && existing.After(until)) {
+			return nil
+		}
+	}
+	s.revoked[revocationID] = until
+	return nil
+}
+
+// CheckRevocation returns [prototokens.ErrTokenRevoked] if the token has been revoked
+func (s *Storer) CheckRevocation(ctx context.Context, revocationID string) error {
+	_, span := internal.StartSpan(ctx, "CheckRevocation")
+	defer span.End()
+	// an expired revocation that hasn't been evicted yet is still a revocation
+	// the token itself will fail validation for being expired anyway
+	s.mu.RLock()
+	_, ok := s.revoked[revocationID]
+	s.mu.RUnlock()
+	if ok {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeSID permanently bans the sid revoking every token with it including tokens issued in the future
+// there is no way to undo it. use [Storer.RevokeIssuedBefore] with the current time to log a sid out
+func (s *Storer) RevokeSID(ctx context.Context, sid string) error {
+	_, span := internal.StartSpan(ctx, "RevokeSID")
+	defer span.End()
+	if sid == "" {
+		return fmt.Errorf("sid cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sids[sid] = struct{}{}
+	return nil
+}
+
+// RevokeIssuedBefore revokes every token with the provided sid issued before the cutoff
+// an empty sid revokes every token issued before the cutoff
+// an existing cutoff is never moved backwards
+func (s *Storer) RevokeIssuedBefore(ctx context.Context, sid string, cutoff time.Time) error {
+	_, span := internal.StartSpan(ctx, "RevokeIssuedBefore")
+	defer span.End()
+	if cutoff.IsZero() {
+		return fmt.Errorf("cutoff cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.cutoffs[sid]; ok && !cutoff.After(existing) {
+		return nil
+	}
+	s.cutoffs[sid] = cutoff
+	return nil
+}
+
+// CheckBulkRevocation returns [prototokens.ErrTokenRevoked] if the sid has been revoked
+// or the token was issued before the cutoff for its sid or the global cutoff
+func (s *Storer) CheckBulkRevocation(ctx context.Context, sid string, issuedAt time.Time) error {
+	_, span := internal.StartSpan(ctx, "CheckBulkRevocation")
+	defer span.End()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sid != "" {
+		if _, ok := s.sids[sid]; ok {
+			return prototokens.ErrTokenRevoked
+		}
+		if cutoff, ok := s.cutoffs[sid]; ok && issuedAt.Before(cutoff) {
+			return prototokens.ErrTokenRevoked
+		}
+	}
+	if cutoff, ok := s.cutoffs[""]; ok && issuedAt.Before(cutoff) {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
+
+// Close stops background eviction
+func (s *Storer) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+func (s *Storer) evictLoop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.evict(s.clock.Now())
+		}
+	}
+}
+
+func (s *Storer) evict(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, until := range s.revoked {
+		if !until.IsZero() && now.After(until) {
+			delete(s.revoked, id)
+		}
+	}
+}
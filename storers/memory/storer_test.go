@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.ExpiringRevocationStorer)(nil), &Storer{}, "should implement the interface")
+	require.Implements(t, (*prototokens.BulkRevocationStorer)(nil), &Storer{}, "should implement the interface")
+}
+
+func TestBulkRevoke(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.CheckBulkRevocation(ctx, "sid", now))
+	require.NoError(t, s.RevokeSID(ctx, "sid"))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "sid", now.Add(time.Hour)), prototokens.ErrTokenRevoked, "should revoke future tokens for the sid")
+	require.NoError(t, s.CheckBulkRevocation(ctx, "other", now))
+	require.Error(t, s.RevokeSID(ctx, ""), "should not allow empty sids")
+
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Second)), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckBulkRevocation(ctx, "other", now), "tokens issued at or after the cutoff should be valid")
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now.Add(-1*time.Hour)), "should not move the cutoff backwards")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Second)), prototokens.ErrTokenRevoked)
+
+	require.NoError(t, s.CheckBulkRevocation(ctx, "", now.Add(-1*time.Hour)))
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "", now.Add(-30*time.Minute)))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply without a sid")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "third", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply to every sid")
+	require.Error(t, s.RevokeIssuedBefore(ctx, "", time.Time{}), "should require a cutoff")
+}
+
+func TestRevoke(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	require.NoError(t, s.CheckRevocation(ctx, t.Name()), "should not be revoked")
+	require.NoError(t, s.Revoke(ctx, t.Name()))
+	require.ErrorIs(t, s.CheckRevocation(ctx, t.Name()), prototokens.ErrTokenRevoked)
+	require.Error(t, s.Revoke(ctx, ""), "should not allow empty ids")
+}
+
+func TestEviction(t *testing.T) {
+	s, err := New(WithEvictionInterval(time.Hour))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RevokeUntil(ctx, "expired", now.Add(-1*time.Minute)))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(1*time.Minute)))
+	require.NoError(t, s.Revoke(ctx, "forever"))
+	// an existing longer revocation should never be shortened
+	require.NoError(t, s.RevokeUntil(ctx, "forever", now.Add(-1*time.Minute)))
+
+	require.ErrorIs(t, s.CheckRevocation(ctx, "expired"), prototokens.ErrTokenRevoked, "should be revoked until evicted")
+	s.evict(now)
+	require.NoError(t, s.CheckRevocation(ctx, "expired"), "should be evicted")
+	require.ErrorIs(t, s.CheckRevocation(ctx, "current"), prototokens.ErrTokenRevoked)
+	require.ErrorIs(t, s.CheckRevocation(ctx, "forever"), prototokens.ErrTokenRevoked)
+	require.Len(t, s.revoked, 2)
+}
+
+func TestBackgroundEviction(t *testing.T) {
+	s, err := New(WithEvictionInterval(10 * time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	require.NoError(t, s.RevokeUntil(ctx, t.Name(), time.Now().UTC()))
+	require.Eventually(t, func() bool {
+		return s.CheckRevocation(ctx, t.Name()) == nil
+	}, time.Second, 10*time.Millisecond, "should be evicted in the background")
+}
+
+func TestEvictionClock(t *testing.T) {
+	now := time.Now().UTC()
+	s, err := New(WithEvictionInterval(10*time.Millisecond), WithClock(prototokens.ClockFunc(func() time.Time {
+		return now.Add(2 * time.Hour)
+	})))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	require.NoError(t, s.RevokeUntil(ctx, t.Name(), now.Add(time.Hour)))
+	require.Eventually(t, func() bool {
+		return s.CheckRevocation(ctx, t.Name()) == nil
+	}, time.Second, 10*time.Millisecond, "should be evicted using the provided clock")
+}
+
+func TestInvalidOpts(t *testing.T) {
+	s, err := New(WithEvictionInterval(0))
+	require.Error(t, err)
+	require.Nil(t, s)
+
+	s, err = New(WithClock(nil))
+	require.Error(t, err)
+	require.Nil(t, s)
+}
+
+func TestParallel(t *testing.T) {
+	s, err := New(WithEvictionInterval(time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	until := time.Now().Add(time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("%s_%d", t.Name(), i)
+			if err := s.RevokeUntil(ctx, id, until); err != nil {
+				t.Error(err)
+			}
+			for j := 0; j < 100; j++ {
+				if err := s.CheckRevocation(ctx, id); err == nil {
+					t.Errorf("%s should be revoked", id)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,7 @@
+// Package revocationlist implements [prototokens.RevocationStorer] for offline verifiers via signed [tokenpb.RevocationList]s
+//
+// Issuers publish a list with [NewList] which wraps the [tokenpb.RevocationList] in a [tokenpb.ProtoToken]
+// restricted to [tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST] and signs it with their [prototokens.TokenManager].
+// Since lists are published, [prototokens.ManagerConfig.ValidateToken] never accepts one as a bearer token unless that usage was explicitly requested.
+// Verifiers poll for the encoded list and pass it to [Storer.Load] which refuses lists that are unsigned, expired or older than the current list.
+package revocationlist
@@ -0,0 +1,197 @@
+package revocationlist
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewList signs a [tokenpb.RevocationList] with the provided [prototokens.TokenManager]
+// the list is valid for the provided duration after which verifiers will refuse it
+// the token is restricted to [tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST] so it is rejected everywhere
+// except [prototokens.Validator.GetValidatedTokenFor] and the ValidFor methods when passed that usage
+// if the list has no issued_at, it is set to now
+func NewList(ctx context.Context, tm prototokens.TokenManager, list *tokenpb.RevocationList, validFor time.Duration) (*tokenpb.SignedToken, error) {
+	ctx, span := internal.StartSpan(ctx, "NewList")
+	defer span.End()
+	if list.GetSequence() == 0 {
+		return nil, fmt.Errorf("sequence must be provided")
+	}
+	list = proto.Clone(list).(*tokenpb.RevocationList)
+	if list.GetIssuedAt() == nil {
+		list.IssuedAt = timestamppb.New(time.Now().UTC())
+	}
+	b, err := proto.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", prototokens.ErrMarshal, err)
+	}
+	pt, err := prototokens.New(validFor,
+		prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST),
+		prototokens.WithVendor(b),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tm.Sign(ctx, pt)
+}
+
+// listValidator is how lists are validated since a token restricted to [tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST]
+// can only be parsed by explicitly asking for that usage. every manager embedding [prototokens.Validator] implements it
+type listValidator interface {
+	GetValidatedTokenFor(context.Context, *tokenpb.SignedToken, tokenpb.TokenUsages) (*tokenpb.ProtoToken, error)
+}
+
+// Storer is an implementation of [prototokens.RevocationStorer] that:
+// - checks revocation against the most recently loaded [tokenpb.RevocationList]
+// - verifies lists with a [prototokens.TokenManager] before loading them
+// - refuses lists with a lower sequence than the current list
+// - fails every check with [prototokens.ErrStaleRevocationList] if no list is loaded or the current list has expired
+//
+// The [prototokens.TokenManager] used to verify lists MUST NOT be configured to use this [Storer]
+// for revocation otherwise no list could ever be loaded
+type Storer struct {
+	mu       sync.RWMutex
+	tm       prototokens.TokenManager
+	lists    listValidator
+	maxAge   time.Duration
+	clock    prototokens.Clock
+	sequence uint64
+	expires  time.Time
+	list     []byte
+	revoked  map[string]struct{}
+}
+
+// Opt is an option for configuring a [Storer]
+type Opt func(*Storer) error
+
+// WithMaxAge refuses lists issued longer than the provided duration ago
+// regardless of how long the list itself is valid for
+func WithMaxAge(d time.Duration) Opt {
+	return func(s *Storer) error {
+		if d <= 0 {
+			return fmt.Errorf("max age must be positive")
+		}
+		s.maxAge = d
+		return nil
+	}
+}
+
+// WithClock uses the provided [prototokens.Clock] when checking list age and expiry
+func WithClock(c prototokens.Clock) Opt {
+	return func(s *Storer) error {
+		if c == nil {
+			return fmt.Errorf("clock cannot be nil")
+		}
+		s.clock = c
+		return nil
+	}
+}
+
+// New returns a new [revocationlist.Storer] that verifies lists with the provided [prototokens.TokenManager]
+// the manager must also provide GetValidatedTokenFor which every manager embedding [prototokens.Validator] does
+func New(tm prototokens.TokenManager, opts ...Opt) (*Storer, error) {
+	if tm == nil {
+		return nil, fmt.Errorf("token manager cannot be nil")
+	}
+	lists, ok := tm.(listValidator)
+	if !ok {
+		return nil, fmt.Errorf("token manager must implement GetValidatedTokenFor to verify lists")
+	}
+	s := &Storer{
+		tm:      tm,
+		lists:   lists,
+		clock:   prototokens.SystemClock,
+		revoked: map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Load verifies a signed list and replaces the current list with it
+// loading the same list again is not an error so callers can poll without tracking what they've loaded
+func (s *Storer) Load(ctx context.Context, st *tokenpb.SignedToken) error {
+	ctx, span := internal.StartSpan(ctx, "Load")
+	defer span.End()
+	pt, err := s.lists.GetValidatedTokenFor(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST)
+	if err != nil {
+		return err
+	}
+	list := &tokenpb.RevocationList{}
+	if err := proto.Unmarshal(pt.GetVendor(), list); err != nil {
+		return fmt.Errorf("%w: %w", prototokens.ErrUnmarshal, err)
+	}
+	if s.maxAge > 0 && s.clock.Now().Sub(list.GetIssuedAt().AsTime()) > s.maxAge {
+		return fmt.Errorf("%w: issued at %s", prototokens.ErrStaleRevocationList, list.GetIssuedAt().AsTime().UTC())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case list.GetSequence() < s.sequence:
+		return fmt.Errorf("%w: have sequence %d got %d", prototokens.ErrStaleRevocationList, s.sequence, list.GetSequence())
+	case list.GetSequence() == s.sequence && s.list != nil && !bytes.Equal(pt.GetVendor(), s.list):
+		return fmt.Errorf("%w: conflicting lists for sequence %d", prototokens.ErrNotValid, list.GetSequence())
+	}
+	revoked := make(map[string]struct{}, len(list.GetRevokedIds()))
+	for _, id := range list.GetRevokedIds() {
+		revoked[id] = struct{}{}
+	}
+	s.sequence = list.GetSequence()
+	s.expires = pt.GetTimestamps().GetNotValidAfter().AsTime().UTC()
+	s.list = pt.GetVendor()
+	s.revoked = revoked
+	return nil
+}
+
+// LoadEncoded decodes a list with the [prototokens.TokenManager] and loads it
+func (s *Storer) LoadEncoded(ctx context.Context, encoded string) error {
+	st, err := s.tm.Decode(ctx, encoded)
+	if err != nil {
+		return err
+	}
+	return s.Load(ctx, st)
+}
+
+// Sequence returns the sequence of the current list or 0 if no list has been loaded
+func (s *Storer) Sequence() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sequence
+}
+
+// Revoke is not supported by verifiers. Revocations are published by the issuer with [NewList]
+func (s *Storer) Revoke(_ context.Context, _ string) error {
+	return fmt.Errorf("%w: revocation lists are read-only. publish a new list instead", prototokens.ErrUnimplemented)
+}
+
+// CheckRevocation returns [prototokens.ErrTokenRevoked] if the token is in the current list
+// it fails closed with [prototokens.ErrStaleRevocationList] if there is no current list
+func (s *Storer) CheckRevocation(ctx context.Context, revocationID string) error {
+	_, span := internal.StartSpan(ctx, "CheckRevocation")
+	defer span.End()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.list == nil {
+		return fmt.Errorf("%w: no revocation list loaded", prototokens.ErrStaleRevocationList)
+	}
+	if s.clock.Now().After(s.expires) {
+		return fmt.Errorf("%w: expired at %s", prototokens.ErrStaleRevocationList, s.expires)
+	}
+	if _, ok := s.revoked[revocationID]; ok {
+		return prototokens.ErrTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package revocationlist
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/managers/ed25519url"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+	"github.com/lusis/prototokens/prototokenstest"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.RevocationStorer)(nil), &Storer{}, "should implement the interface")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil)
+	require.Error(t, err, "should require a manager")
+	_, err = New(&prototokens.UnimplementedTokenManager{})
+	require.Error(t, err, "should require a manager that can validate lists")
+}
+
+func TestLoad(t *testing.T) {
+	tm := newManager(t)
+	s, err := New(tm)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.ErrorIs(t, s.CheckRevocation(ctx, "revoked"), prototokens.ErrStaleRevocationList, "should fail closed without a list")
+	require.ErrorIs(t, s.Revoke(ctx, "revoked"), prototokens.ErrUnimplemented)
+
+	st, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 2, RevokedIds: []string{"revoked"}}, time.Hour)
+	require.NoError(t, err)
+	encoded, err := tm.Encode(ctx, st)
+	require.NoError(t, err)
+	require.NoError(t, s.LoadEncoded(ctx, encoded))
+	require.NoError(t, s.LoadEncoded(ctx, encoded), "reloading the same list should not error")
+	require.Equal(t, uint64(2), s.Sequence())
+	require.ErrorIs(t, s.CheckRevocation(ctx, "revoked"), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckRevocation(ctx, "valid"))
+
+	older, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 1}, time.Hour)
+	require.NoError(t, err)
+	require.ErrorIs(t, s.Load(ctx, older), prototokens.ErrStaleRevocationList, "should refuse older lists")
+
+	conflict, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 2, RevokedIds: []string{"other"}}, time.Hour)
+	require.NoError(t, err)
+	require.ErrorIs(t, s.Load(ctx, conflict), prototokens.ErrNotValid, "should refuse conflicting lists")
+
+	newer, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 3, RevokedIds: []string{"valid"}}, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.Load(ctx, newer))
+	require.NoError(t, s.CheckRevocation(ctx, "revoked"), "should only use the current list")
+	require.ErrorIs(t, s.CheckRevocation(ctx, "valid"), prototokens.ErrTokenRevoked)
+}
+
+func TestRefuse(t *testing.T) {
+	tm := newManager(t)
+	ctx := context.Background()
+
+	t.Run("unsigned", func(t *testing.T) {
+		s, err := New(tm)
+		require.NoError(t, err)
+		st, err := NewList(ctx, newManager(t), &tokenpb.RevocationList{Sequence: 1}, time.Hour)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Load(ctx, st), prototokens.ErrTamper, "should refuse lists signed with another key")
+		st.Signature = nil
+		require.ErrorIs(t, s.Load(ctx, st), prototokens.ErrInvalidSignature, "should refuse unsigned lists")
+	})
+	t.Run("wrong-usage", func(t *testing.T) {
+		s, err := New(tm)
+		require.NoError(t, err)
+		pt, err := prototokens.New(time.Hour, prototokens.WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+		require.NoError(t, err)
+		st, err := tm.Sign(ctx, pt)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Load(ctx, st), prototokens.ErrNotValidForUsage)
+	})
+	t.Run("max-age", func(t *testing.T) {
+		s, err := New(tm, WithMaxAge(time.Minute))
+		require.NoError(t, err)
+		st, err := NewList(ctx, tm, &tokenpb.RevocationList{
+			Sequence: 1,
+			IssuedAt: timestamppb.New(time.Now().Add(-1 * time.Hour)),
+		}, 2*time.Hour)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Load(ctx, st), prototokens.ErrStaleRevocationList)
+	})
+	t.Run("expired", func(t *testing.T) {
+		fc := prototokenstest.NewFakeClock(time.Now())
+		s, err := New(tm, WithClock(fc))
+		require.NoError(t, err)
+		st, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 1}, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, s.Load(ctx, st))
+		require.NoError(t, s.CheckRevocation(ctx, t.Name()))
+		fc.Advance(2 * time.Hour)
+		require.ErrorIs(t, s.CheckRevocation(ctx, t.Name()), prototokens.ErrStaleRevocationList, "should fail closed once the list expires")
+	})
+	t.Run("no-sequence", func(t *testing.T) {
+		_, err := NewList(ctx, tm, &tokenpb.RevocationList{}, time.Hour)
+		require.Error(t, err)
+	})
+}
+
+func TestWithManager(t *testing.T) {
+	issuer := newManager(t)
+	ctx := context.Background()
+	s, err := New(issuer)
+	require.NoError(t, err)
+
+	keydata := newKey(t)
+	verifier, err := ed25519url.New(func(_ context.Context) []byte { return keydata }, prototokens.WithRevocationStorer(s))
+	require.NoError(t, err)
+	pt, err := prototokens.New(time.Hour)
+	require.NoError(t, err)
+	st, err := verifier.Sign(ctx, pt)
+	require.NoError(t, err)
+	require.ErrorIs(t, verifier.Validate(ctx, st), prototokens.ErrRevocationCheck, "should fail closed without a list")
+
+	list, err := NewList(ctx, issuer, &tokenpb.RevocationList{Sequence: 1, RevokedIds: []string{pt.GetId()}}, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, s.Load(ctx, list))
+	require.ErrorIs(t, verifier.Validate(ctx, st), prototokens.ErrTokenRevoked)
+}
+
+func TestListIsNotAToken(t *testing.T) {
+	tm := newManager(t)
+	ctx := context.Background()
+	st, err := NewList(ctx, tm, &tokenpb.RevocationList{Sequence: 1}, time.Hour)
+	require.NoError(t, err)
+	encoded, err := tm.Encode(ctx, st)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, tm.Validate(ctx, st), prototokens.ErrNotValidForUsage)
+	_, err = tm.GetValidatedToken(ctx, st)
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+	_, err = tm.ValidateAndParse(ctx, encoded)
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage)
+	_, err = tm.ValidateAndParse(ctx, encoded, prototokens.RequireUsages(tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST))
+	require.ErrorIs(t, err, prototokens.ErrNotValidForUsage, "requirements can't opt in to lists")
+	require.ErrorIs(t, tm.ValidFor(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_HUMAN), prototokens.ErrNotValidForUsage)
+	require.ErrorIs(t, tm.ValidForScopes(ctx, st, "repo:read"), prototokens.ErrNotValidForUsage)
+	var ve *prototokens.ValidationError
+	require.ErrorAs(t, tm.Validate(ctx, st), &ve)
+	require.Equal(t, prototokens.ReasonUsage, ve.Reason)
+
+	// only explicitly asking for the usage works
+	require.NoError(t, tm.ValidFor(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST))
+	pt, err := tm.(listValidator).GetValidatedTokenFor(ctx, st, tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST)
+	require.NoError(t, err)
+	require.NotEmpty(t, pt.GetVendor())
+}
+
+func newManager(t *testing.T) prototokens.TokenManager {
+	t.Helper()
+	keydata := newKey(t)
+	m, err := ed25519url.New(func(_ context.Context) []byte { return keydata })
+	require.NoError(t, err)
+	return m
+}
+
+func newKey(t *testing.T) []byte {
+	t.Helper()
+	keydata := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, keydata)
+	require.NoError(t, err)
+	return keydata
+}
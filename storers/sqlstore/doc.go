@@ -0,0 +1,3 @@
+// Package sqlstore implements [prototokens.RevocationStorer] on top of [database/sql]
+// it works with any driver (sqlite, postgres, mysql) so revocations survive restarts and can be shared across replicas
+package sqlstore
@@ -0,0 +1,335 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lusis/prototokens"
+	"github.com/lusis/prototokens/internal"
+)
+
+// DefaultTableName is the name of the table revocations are stored in if not otherwise provided
+const DefaultTableName = "prototokens_revocations"
+
+// PlaceholderStyle is the style of bind parameters the database driver expects
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion uses ? for bind parameters (sqlite, mysql)
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar uses $1, $2... for bind parameters (postgres)
+	PlaceholderDollar
+)
+
+var validTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// migrations are applied in order by [Storer.Migrate]
+// never edit an existing migration. append a new one instead
+// timestamps are stored as integers so comparisons behave the same on every driver
+// every timestamp is unix seconds except revoked_before which is unix nanoseconds so cutoffs compare exactly with issued_at
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS %[1]s (
+		revocation_id VARCHAR(255) NOT NULL PRIMARY KEY,
+		revoked_at BIGINT NOT NULL,
+		expires_at BIGINT NULL
+	)`,
+	// revoked_before is NULL when every token for the sid is revoked
+	// the global cutoff is stored with an empty sid
+	`CREATE TABLE IF NOT EXISTS %[1]s_cutoffs (
+		sid VARCHAR(255) NOT NULL PRIMARY KEY,
+		revoked_at BIGINT NOT NULL,
+		revoked_before BIGINT NULL
+	)`,
+}
+
+// Storer is an implementation of [prototokens.ExpiringRevocationStorer] and [prototokens.BulkRevocationStorer] that:
+// - stores revocations in a [sql.DB]
+// - records when a token was revoked and when the revocation can be pruned
+// - stores sid revocations and cutoffs in a separate table suffixed with _cutoffs
+// - requires [Storer.Migrate] to be called before use
+type Storer struct {
+	db          *sql.DB
+	table       string
+	placeholder PlaceholderStyle
+}
+
+// Opt is an option for configuring a [Storer]
+type Opt func(*Storer) error
+
+// WithTableName sets the table revocations are stored in
+// the schema version table will be the table name suffixed with _schema_version
+func WithTableName(name string) Opt {
+	return func(s *Storer) error {
+		if !validTableName.MatchString(name) {
+			return fmt.Errorf("invalid table name: %q", name)
+		}
+		s.table = name
+		return nil
+	}
+}
+
+// WithPlaceholderStyle sets the bind parameter style for the database driver
+func WithPlaceholderStyle(style PlaceholderStyle) Opt {
+	return func(s *Storer) error {
+		switch style {
+		case PlaceholderQuestion, PlaceholderDollar:
+			s.placeholder = style
+			return nil
+		default:
+			return fmt.Errorf("unknown placeholder style: %d", style)
+		}
+	}
+}
+
+// New returns a new [sqlstore.Storer]
+func New(db *sql.DB, opts ...Opt) (*Storer, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+	s := &Storer{
+		db:    db,
+		table: DefaultTableName,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Migrate creates or updates the schema
+// it is safe to call on every startup
+func (s *Storer) Migrate(ctx context.Context) error {
+	ctx, span := internal.StartSpan(ctx, "Migrate")
+	defer span.End()
+	versionTable := s.table + "_schema_version"
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+versionTable+" (version INTEGER NOT NULL)"); err != nil {
+		return fmt.Errorf("unable to create schema version table: %w", err)
+	}
+	var current sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(version) FROM "+versionTable).Scan(&current); err != nil {
+		return fmt.Errorf("unable to read schema version: %w", err)
+	}
+	for i := int(current.Int64); i < len(migrations); i++ {
+		if err := s.migrate(ctx, versionTable, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate applies a single migration and records its version in one transaction
+// so a failure part way through never leaves a migration applied but unrecorded
+func (s *Storer) migrate(ctx context.Context, versionTable string, i int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to apply migration %d: %w", i+1, err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(migrations[i], s.table)); err != nil {
+		return fmt.Errorf("unable to apply migration %d: %w", i+1, err)
+	}
+	if _, err := tx.ExecContext(ctx, s.query("INSERT INTO "+versionTable+" (version) VALUES (?)"), i+1); err != nil {
+		return fmt.Errorf("unable to record migration %d: %w", i+1, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to record migration %d: %w", i+1, err)
+	}
+	return nil
+}
+
+// Revoke revokes a token by its identifier forever
+// prefer [Storer.RevokeUntil] so that revocations can be pruned
+func (s *Storer) Revoke(ctx context.Context, revocationID string) error {
+	return s.RevokeUntil(ctx, revocationID, time.Time{})
+}
+
+// RevokeUntil revokes a token by its identifier until the provided time
+// a zero time means the revocation never expires
+// an existing revocation is never shortened
+func (s *Storer) RevokeUntil(ctx context.Context, revocationID string, until time.Time) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeUntil")
+	defer span.End()
+	if revocationID == "" {
+		return fmt.Errorf("revocation id cannot be empty")
+	}
+	var expires sql.NullInt64
+	if !until.IsZero() {
+		// round up so a revocation is never pruned before the token expires
+		expires = sql.NullInt64{Int64: until.Add(time.Second - 1).Unix(), Valid: true}
+	}
+
+	updated, err := s.extend(ctx, revocationID, expires)
+	if err != nil || updated {
+		return err
+	}
+	_, insertErr := s.db.ExecContext(ctx,
+		s.query("INSERT INTO "+s.table+" (revocation_id, revoked_at, expires_at) VALUES (?, ?, ?)"),
+		revocationID, time.Now().UTC().Unix(), expires,
+	)
+	if insertErr == nil {
+		return nil
+	}
+	// another replica may have revoked the same token between our check and insert
+	if updated, err := s.extend(ctx, revocationID, expires); err == nil && updated {
+		return nil
+	}
+	return fmt.Errorf("unable to store revocation: %w", insertErr)
+}
+
+// CheckRevocation returns [prototokens.ErrTokenRevoked] if the token has been revoked
+func (s *Storer) CheckRevocation(ctx context.Context, revocationID string) error {
+	ctx, span := internal.StartSpan(ctx, "CheckRevocation")
+	defer span.End()
+	var one int
+	err := s.db.QueryRowContext(ctx, s.query("SELECT 1 FROM "+s.table+" WHERE revocation_id = ?"), revocationID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to check revocation: %w", err)
+	}
+	return prototokens.ErrTokenRevoked
+}
+
+// RevokeSID permanently bans the sid revoking every token with it including tokens issued in the future
+// there is no way to undo it. use [Storer.RevokeIssuedBefore] with the current time to log a sid out
+func (s *Storer) RevokeSID(ctx context.Context, sid string) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeSID")
+	defer span.End()
+	if sid == "" {
+		return fmt.Errorf("sid cannot be empty")
+	}
+	return s.setCutoff(ctx, sid, sql.NullInt64{})
+}
+
+// RevokeIssuedBefore revokes every token with the provided sid issued before the cutoff
+// an empty sid revokes every token issued before the cutoff
+// the cutoff is compared exactly with a token's issued_at and an existing cutoff is never moved backwards
+func (s *Storer) RevokeIssuedBefore(ctx context.Context, sid string, cutoff time.Time) error {
+	ctx, span := internal.StartSpan(ctx, "RevokeIssuedBefore")
+	defer span.End()
+	if cutoff.IsZero() {
+		return fmt.Errorf("cutoff cannot be empty")
+	}
+	return s.setCutoff(ctx, sid, sql.NullInt64{Int64: cutoff.UnixNano(), Valid: true})
+}
+
+// CheckBulkRevocation returns [prototokens.ErrTokenRevoked] if the sid has been revoked
+// or the token was issued before the cutoff for its sid or the global cutoff
+func (s *Storer) CheckBulkRevocation(ctx context.Context, sid string, issuedAt time.Time) error {
+	ctx, span := internal.StartSpan(ctx, "CheckBulkRevocation")
+	defer span.End()
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT sid, revoked_before FROM "+s.table+"_cutoffs WHERE sid IN (?, '')"), sid)
+	if err != nil {
+		return fmt.Errorf("unable to check revocation: %w", err)
+	}
+	defer rows.Close() // nolint: errcheck
+	for rows.Next() {
+		var rowSID string
+		var before sql.NullInt64
+		if err := rows.Scan(&rowSID, &before); err != nil {
+			return fmt.Errorf("unable to check revocation: %w", err)
+		}
+		if (!before.Valid && rowSID != "") || (before.Valid && issuedAt.UnixNano() < before.Int64) {
+			return prototokens.ErrTokenRevoked
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("unable to check revocation: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes revocations for tokens that would have expired before the provided time
+// it returns the number of revocations deleted
+func (s *Storer) Prune(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := internal.StartSpan(ctx, "Prune")
+	defer span.End()
+	res, err := s.db.ExecContext(ctx,
+		s.query("DELETE FROM "+s.table+" WHERE expires_at IS NOT NULL AND expires_at < ?"),
+		before.Unix(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("unable to prune revocations: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// extend updates an existing revocation if the new expiry is later than the stored one
+// it returns true if the revocation exists
+func (s *Storer) extend(ctx context.Context, revocationID string, expires sql.NullInt64) (bool, error) {
+	var current sql.NullInt64
+	err := s.db.QueryRowContext(ctx, s.query("SELECT expires_at FROM "+s.table+" WHERE revocation_id = ?"), revocationID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to read revocation: %w", err)
+	}
+	if !current.Valid || (expires.Valid && expires.Int64 <= current.Int64) {
+		return true, nil
+	}
+	if _, err := s.db.ExecContext(ctx, s.query("UPDATE "+s.table+" SET expires_at = ? WHERE revocation_id = ?"), expires, revocationID); err != nil {
+		return true, fmt.Errorf("unable to update revocation: %w", err)
+	}
+	return true, nil
+}
+
+// setCutoff stores a cutoff for the sid
+// a NULL cutoff revokes every token for the sid and is never replaced
+func (s *Storer) setCutoff(ctx context.Context, sid string, before sql.NullInt64) error {
+	var current sql.NullInt64
+	err := s.db.QueryRowContext(ctx, s.query("SELECT revoked_before FROM "+s.table+"_cutoffs WHERE sid = ?"), sid).Scan(&current)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, insertErr := s.db.ExecContext(ctx,
+			s.query("INSERT INTO "+s.table+"_cutoffs (sid, revoked_at, revoked_before) VALUES (?, ?, ?)"),
+			sid, time.Now().UTC().Unix(), before,
+		)
+		if insertErr == nil {
+			return nil
+		}
+		// another replica may have inserted the same sid between our check and insert
+		if err := s.db.QueryRowContext(ctx, s.query("SELECT revoked_before FROM "+s.table+"_cutoffs WHERE sid = ?"), sid).Scan(&current); err != nil {
+			return fmt.Errorf("unable to store revocation: %w", insertErr)
+		}
+	case err != nil:
+		return fmt.Errorf("unable to read revocation: %w", err)
+	}
+	if !current.Valid || (before.Valid && before.Int64 <= current.Int64) {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx,
+		s.query("UPDATE "+s.table+"_cutoffs SET revoked_before = ?, revoked_at = ? WHERE sid = ?"),
+		before, time.Now().UTC().Unix(), sid,
+	); err != nil {
+		return fmt.Errorf("unable to update revocation: %w", err)
+	}
+	return nil
+}
+
+// query rewrites ? placeholders for the configured [PlaceholderStyle]
+func (s *Storer) query(q string) string {
+	if s.placeholder != PlaceholderDollar {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
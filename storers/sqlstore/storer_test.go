@@ -0,0 +1,138 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lusis/prototokens"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestImplements(t *testing.T) {
+	require.Implements(t, (*prototokens.ExpiringRevocationStorer)(nil), &Storer{}, "should implement the interface")
+	require.Implements(t, (*prototokens.BulkRevocationStorer)(nil), &Storer{}, "should implement the interface")
+}
+
+func TestBulkRevoke(t *testing.T) {
+	s := setupTest(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.CheckBulkRevocation(ctx, "sid", now))
+	require.NoError(t, s.RevokeSID(ctx, "sid"))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "sid", now.Add(time.Hour)), prototokens.ErrTokenRevoked, "should revoke future tokens for the sid")
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "sid", now), "should not replace a sid revocation")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "sid", now.Add(time.Hour)), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckBulkRevocation(ctx, "other", now))
+	require.Error(t, s.RevokeSID(ctx, ""), "should not allow empty sids")
+
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Second)), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.CheckBulkRevocation(ctx, "other", now), "tokens issued at or after the cutoff should be valid")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Nanosecond)), prototokens.ErrTokenRevoked, "cutoffs should be exact")
+	require.NoError(t, s.CheckBulkRevocation(ctx, "other", now.Add(500*time.Millisecond)), "tokens issued later in the same second should be valid")
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "other", now.Add(-1*time.Hour)), "should not move the cutoff backwards")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "other", now.Add(-1*time.Second)), prototokens.ErrTokenRevoked)
+
+	require.NoError(t, s.CheckBulkRevocation(ctx, "", now.Add(-1*time.Hour)))
+	require.NoError(t, s.RevokeIssuedBefore(ctx, "", now.Add(-30*time.Minute)))
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply without a sid")
+	require.ErrorIs(t, s.CheckBulkRevocation(ctx, "third", now.Add(-1*time.Hour)), prototokens.ErrTokenRevoked, "global cutoff should apply to every sid")
+	require.Error(t, s.RevokeIssuedBefore(ctx, "", time.Time{}), "should require a cutoff")
+}
+
+func TestRevoke(t *testing.T) {
+	s := setupTest(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CheckRevocation(ctx, t.Name()), "should not be revoked")
+	require.NoError(t, s.Revoke(ctx, t.Name()))
+	require.ErrorIs(t, s.CheckRevocation(ctx, t.Name()), prototokens.ErrTokenRevoked)
+	require.NoError(t, s.Revoke(ctx, t.Name()), "revoking twice should not error")
+	require.Error(t, s.Revoke(ctx, ""), "should not allow empty ids")
+}
+
+func TestPrune(t *testing.T) {
+	s := setupTest(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RevokeUntil(ctx, "expired", now.Add(-1*time.Hour)))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(1*time.Hour)))
+	require.NoError(t, s.Revoke(ctx, "forever"))
+	// existing revocations should never be shortened
+	require.NoError(t, s.RevokeUntil(ctx, "forever", now.Add(-1*time.Hour)))
+	require.NoError(t, s.RevokeUntil(ctx, "current", now.Add(-1*time.Hour)))
+	// but can be extended
+	require.NoError(t, s.RevokeUntil(ctx, "expired", now.Add(-30*time.Minute)))
+
+	require.ErrorIs(t, s.CheckRevocation(ctx, "expired"), prototokens.ErrTokenRevoked, "should be revoked until pruned")
+	pruned, err := s.Prune(ctx, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pruned)
+	require.NoError(t, s.CheckRevocation(ctx, "expired"), "should be pruned")
+	require.ErrorIs(t, s.CheckRevocation(ctx, "current"), prototokens.ErrTokenRevoked)
+	require.ErrorIs(t, s.CheckRevocation(ctx, "forever"), prototokens.ErrTokenRevoked)
+}
+
+func TestMigrate(t *testing.T) {
+	s := setupTest(t)
+	ctx := context.Background()
+	require.NoError(t, s.Revoke(ctx, t.Name()))
+	require.NoError(t, s.Migrate(ctx), "migrating twice should be safe")
+	require.ErrorIs(t, s.CheckRevocation(ctx, t.Name()), prototokens.ErrTokenRevoked, "data should survive migration")
+
+	var version int
+	require.NoError(t, s.db.QueryRowContext(ctx, "SELECT MAX(version) FROM "+DefaultTableName+"_schema_version").Scan(&version))
+	require.Equal(t, len(migrations), version)
+}
+
+func TestUnmigrated(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	s, err := New(db)
+	require.NoError(t, err)
+	err = s.CheckRevocation(context.Background(), t.Name())
+	require.Error(t, err)
+	require.NotErrorIs(t, err, prototokens.ErrTokenRevoked)
+}
+
+func TestOpts(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = New(nil)
+	require.Error(t, err, "should require a db")
+	_, err = New(db, WithTableName("bobby; DROP TABLE students"))
+	require.Error(t, err, "should reject invalid table names")
+	_, err = New(db, WithPlaceholderStyle(PlaceholderStyle(99)))
+	require.Error(t, err, "should reject unknown placeholder styles")
+
+	s, err := New(db, WithTableName("custom_revocations"), WithPlaceholderStyle(PlaceholderDollar))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1 FROM custom_revocations WHERE revocation_id = $1 AND x = $2", s.query("SELECT 1 FROM custom_revocations WHERE revocation_id = ? AND x = ?"))
+	// sqlite understands $n placeholders so we can exercise the full path
+	require.NoError(t, s.Migrate(context.Background()))
+	require.NoError(t, s.Revoke(context.Background(), t.Name()))
+	require.ErrorIs(t, s.CheckRevocation(context.Background(), t.Name()), prototokens.ErrTokenRevoked)
+}
+
+func setupTest(t *testing.T) *Storer {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// each connection to :memory: is a different database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	s, err := New(db)
+	require.NoError(t, err)
+	require.NoError(t, s.Migrate(context.Background()))
+	return s
+}
@@ -2,6 +2,8 @@ package prototokens
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
 )
@@ -18,10 +20,76 @@ type TokenManager interface {
 	Validate(context.Context, *tokenpb.SignedToken) error
 	// ValidFor validates if the token can be used for the provided usages
 	ValidFor(context.Context, *tokenpb.SignedToken, tokenpb.TokenUsages) error
+	// ValidForAll validates if the token can be used for every one of the provided usages
+	ValidForAll(context.Context, *tokenpb.SignedToken, ...tokenpb.TokenUsages) error
+	// ValidForAny validates if the token can be used for at least one of the provided usages
+	ValidForAny(context.Context, *tokenpb.SignedToken, ...tokenpb.TokenUsages) error
+	// ValidForScopes validates if the token's scopes satisfy every provided scope
+	ValidForScopes(context.Context, *tokenpb.SignedToken, ...string) error
 	// GetValidatedToken turns a [tokenpb.SignedToken] into a [tokenpb.ProtoToken] after validation
 	GetValidatedToken(context.Context, *tokenpb.SignedToken) (*tokenpb.ProtoToken, error)
 	// RevokeToken revokes a token
 	RevokeToken(context.Context, *tokenpb.ProtoToken) error
+	// ValidateAndParse decodes, verifies and validates an encoded token against the provided requirements in a single pass
+	// returning the trusted [tokenpb.ProtoToken]. failures are returned as a [ValidationError]
+	ValidateAndParse(context.Context, string, ...Requirement) (*tokenpb.ProtoToken, error)
+}
+
+// CheckUsage returns [ErrNotValidForUsage] if the token is not valid for the provided usage
+// the error is a [ValidationError] with [ReasonUsage]
+func CheckUsage(tok *tokenpb.ProtoToken, usage tokenpb.TokenUsages) error {
+	return CheckUsages(tok, usage)
+}
+
+// CheckUsages returns [ErrNotValidForUsage] listing every provided usage the token is not valid for
+// providing no usages always fails. the error is a [ValidationError] with [ReasonUsage]
+func CheckUsages(tok *tokenpb.ProtoToken, usages ...tokenpb.TokenUsages) error {
+	if len(usages) == 0 {
+		return tokenError(tok, ReasonUsage, 0, fmt.Errorf("%w: no usages provided", ErrNotValidForUsage))
+	}
+	var missing []tokenpb.TokenUsages
+	for _, usage := range usages {
+		if !hasUsage(tok, usage) {
+			missing = append(missing, usage)
+		}
+	}
+	if len(missing) > 0 {
+		return tokenError(tok, ReasonUsage, 0, fmt.Errorf("%w: missing %s", ErrNotValidForUsage, joinUsages(missing)))
+	}
+	return nil
+}
+
+// CheckAnyUsage returns [ErrNotValidForUsage] unless the token is valid for at least one of the provided usages
+// providing no usages always fails. the error is a [ValidationError] with [ReasonUsage]
+func CheckAnyUsage(tok *tokenpb.ProtoToken, usages ...tokenpb.TokenUsages) error {
+	for _, usage := range usages {
+		if hasUsage(tok, usage) {
+			return nil
+		}
+	}
+	return tokenError(tok, ReasonUsage, 0, fmt.Errorf("%w: missing any of %s", ErrNotValidForUsage, joinUsages(usages)))
+}
+
+func hasUsage(tok *tokenpb.ProtoToken, usage tokenpb.TokenUsages) bool {
+	return containsUsage(tok.GetUsages(), usage)
+}
+
+func containsUsage(usages []tokenpb.TokenUsages, usage tokenpb.TokenUsages) bool {
+	for _, u := range usages {
+		if u == usage {
+			// got a hit
+			return true
+		}
+	}
+	return false
+}
+
+func joinUsages(usages []tokenpb.TokenUsages) string {
+	names := make([]string, 0, len(usages))
+	for _, u := range usages {
+		names = append(names, u.String())
+	}
+	return strings.Join(names, ", ")
 }
 
 // UnimplementedTokenManager is a TokenManager implementation designed to be
@@ -53,6 +121,21 @@ func (up *UnimplementedTokenManager) ValidFor(_ context.Context, _ *tokenpb.Sign
 	return ErrUnimplemented
 }
 
+// ValidForAll validates if the token can be used for every one of the provided usages
+func (up *UnimplementedTokenManager) ValidForAll(_ context.Context, _ *tokenpb.SignedToken, _ ...tokenpb.TokenUsages) error {
+	return ErrUnimplemented
+}
+
+// ValidForAny validates if the token can be used for at least one of the provided usages
+func (up *UnimplementedTokenManager) ValidForAny(_ context.Context, _ *tokenpb.SignedToken, _ ...tokenpb.TokenUsages) error {
+	return ErrUnimplemented
+}
+
+// ValidForScopes validates if the token's scopes satisfy every provided scope
+func (up *UnimplementedTokenManager) ValidForScopes(_ context.Context, _ *tokenpb.SignedToken, _ ...string) error {
+	return ErrUnimplemented
+}
+
 // GetValidatedToken turns a [tokenpb.SignedToken] into a [tokenpb.ProtoToken] after validation
 func (up *UnimplementedTokenManager) GetValidatedToken(_ context.Context, _ *tokenpb.SignedToken) (*tokenpb.ProtoToken, error) {
 	return nil, ErrUnimplemented
@@ -62,3 +145,8 @@ func (up *UnimplementedTokenManager) GetValidatedToken(_ context.Context, _ *tok
 func (up *UnimplementedTokenManager) RevokeToken(_ context.Context, _ *tokenpb.ProtoToken) error {
 	return ErrUnimplemented
 }
+
+// ValidateAndParse decodes, verifies and validates an encoded token in a single pass
+func (up *UnimplementedTokenManager) ValidateAndParse(_ context.Context, _ string, _ ...Requirement) (*tokenpb.ProtoToken, error) {
+	return nil, ErrUnimplemented
+}
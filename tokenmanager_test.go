@@ -0,0 +1,32 @@
+package prototokens
+
+import (
+	"testing"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUsages(t *testing.T) {
+	tok, err := New(time.Hour, WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE))
+	require.NoError(t, err)
+	human, machine, exchange, rotation := tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE, tokenpb.TokenUsages_TOKEN_USAGES_ROTATION
+
+	require.NoError(t, CheckUsage(tok, human))
+	require.ErrorIs(t, CheckUsages(tok), ErrNotValidForUsage, "no usages should fail closed")
+	require.NoError(t, CheckUsages(tok, human, exchange))
+	err = CheckUsages(tok, human, machine, rotation)
+	require.ErrorIs(t, err, ErrNotValidForUsage)
+	require.ErrorContains(t, err, "missing TOKEN_USAGES_MACHINE, TOKEN_USAGES_ROTATION", "should list every missing usage")
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, ReasonUsage, ve.Reason)
+
+	require.NoError(t, CheckAnyUsage(tok, machine, human))
+	err = CheckAnyUsage(tok, machine, rotation)
+	require.ErrorIs(t, err, ErrNotValidForUsage)
+	require.ErrorContains(t, err, "missing any of TOKEN_USAGES_MACHINE, TOKEN_USAGES_ROTATION")
+	require.ErrorIs(t, CheckAnyUsage(tok), ErrNotValidForUsage, "no usages should fail closed")
+}
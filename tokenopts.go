@@ -2,8 +2,13 @@ package prototokens
 
 import (
 	"fmt"
+	"time"
 
 	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TokenOpt is an option for creating a token
@@ -61,10 +66,158 @@ func WithVendor(data []byte) TokenOpt {
 		if data == nil {
 			return fmt.Errorf("data cannot be empty")
 		}
-		if pt.GetVendor() != nil {
+		if pt.GetVendor() != nil || pt.GetVendorMessage() != nil {
 			return fmt.Errorf("%w: vendor", ErrOverwrite)
 		}
 		pt.Vendor = data
 		return nil
 	}
 }
+
+// WithVendorMessage stores msg as a [anypb.Any] in the vendor_message field of the new token
+// use [VendorAs] to get it back out of a validated token. a token carries either vendor bytes or a vendor message
+func WithVendorMessage(msg proto.Message) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if msg == nil {
+			return fmt.Errorf("vendor message cannot be nil")
+		}
+		if pt.GetVendor() != nil || pt.GetVendorMessage() != nil {
+			return fmt.Errorf("%w: vendor", ErrOverwrite)
+		}
+		a, err := anypb.New(msg)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMarshal, err)
+		}
+		pt.VendorMessage = a
+		return nil
+	}
+}
+
+// WithIssuer sets the issuer of the new token
+func WithIssuer(issuer string) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if issuer == "" {
+			return fmt.Errorf("issuer cannot be empty")
+		}
+		if pt.GetIssuer() != "" {
+			return fmt.Errorf("%w: issuer", ErrOverwrite)
+		}
+		pt.Issuer = issuer
+		return nil
+	}
+}
+
+// WithAudience sets the intended audiences of the new token
+func WithAudience(audience ...string) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if len(audience) == 0 {
+			return fmt.Errorf("at least one audience must be provided")
+		}
+		for _, a := range audience {
+			if a == "" {
+				return fmt.Errorf("audience cannot be empty")
+			}
+		}
+		if pt.GetAudience() != nil {
+			return fmt.Errorf("%w: audience", ErrOverwrite)
+		}
+		pt.Audience = audience
+		return nil
+	}
+}
+
+// WithSubject sets the subject of the new token
+func WithSubject(subject string) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if subject == "" {
+			return fmt.Errorf("subject cannot be empty")
+		}
+		if pt.GetSubject() != "" {
+			return fmt.Errorf("%w: subject", ErrOverwrite)
+		}
+		pt.Subject = subject
+		return nil
+	}
+}
+
+// WithScopes sets the scopes of the new token. see [ScopeMatches] for how they are matched
+func WithScopes(scopes ...string) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if len(scopes) == 0 {
+			return fmt.Errorf("at least one scope must be provided")
+		}
+		for _, s := range scopes {
+			if s == "" {
+				return fmt.Errorf("scope cannot be empty")
+			}
+		}
+		if pt.GetScopes() != nil {
+			return fmt.Errorf("%w: scopes", ErrOverwrite)
+		}
+		pt.Scopes = scopes
+		return nil
+	}
+}
+
+// WithTokenClock uses the provided [Clock] instead of the system clock for the timestamps of the new token
+// issued_at is set to the [Clock]'s current time. unless the validity window is set with [WithNotValidBefore]
+// or [WithValidityWindow], the token becomes valid at issued_at for the duration passed to [New]
+func WithTokenClock(c Clock) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if c == nil {
+			return fmt.Errorf("clock cannot be nil")
+		}
+		ts := pt.GetTimestamps()
+		if ts == nil {
+			return fmt.Errorf("token has no timestamps")
+		}
+		if ts.GetIssuedAt() != nil {
+			return fmt.Errorf("%w: issued at", ErrOverwrite)
+		}
+		ts.IssuedAt = timestamppb.New(now(c))
+		return nil
+	}
+}
+
+// WithNotValidBefore delays when the new token becomes valid while still recording when it was issued
+// the token keeps the duration passed to [New] starting from nvb
+// it cannot be combined with [WithValidityWindow]
+func WithNotValidBefore(nvb time.Time) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if nvb.IsZero() {
+			return fmt.Errorf("not valid before cannot be zero")
+		}
+		ts := pt.GetTimestamps()
+		if ts == nil {
+			return fmt.Errorf("token has no timestamps")
+		}
+		if ts.GetNotValidBefore() != nil || ts.GetNotValidAfter() != nil {
+			return fmt.Errorf("%w: validity window", ErrOverwrite)
+		}
+		ts.NotValidBefore = timestamppb.New(nvb.UTC())
+		return nil
+	}
+}
+
+// WithValidityWindow sets exactly when the new token is valid ignoring the duration passed to [New]
+// issued_at is still set to when the token was created. it cannot be combined with [WithNotValidBefore]
+func WithValidityWindow(nvb, nva time.Time) TokenOpt {
+	return func(pt *tokenpb.ProtoToken) error {
+		if nvb.IsZero() || nva.IsZero() {
+			return fmt.Errorf("validity window cannot be zero")
+		}
+		if !nva.After(nvb) {
+			return fmt.Errorf("not valid after must be after not valid before")
+		}
+		ts := pt.GetTimestamps()
+		if ts == nil {
+			return fmt.Errorf("token has no timestamps")
+		}
+		if ts.GetNotValidBefore() != nil || ts.GetNotValidAfter() != nil {
+			return fmt.Errorf("%w: validity window", ErrOverwrite)
+		}
+		ts.NotValidBefore = timestamppb.New(nvb.UTC())
+		ts.NotValidAfter = timestamppb.New(nva.UTC())
+		return nil
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+	"github.com/lusis/prototokens/prototokenstest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,6 +26,46 @@ func TestTokenOpts(t *testing.T) {
 				WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_EXCHANGE),
 				WithSID(t.Name() + "_sid"),
 				WithVendor([]byte("vendor data")),
+				WithIssuer("billing"),
+				WithAudience("billing", "admin"),
+				WithSubject("user:1"),
+				WithScopes("repo:*", "org:read"),
+			},
+		},
+		"invalid-scopes": {
+			err: true,
+			opts: []TokenOpt{
+				WithScopes(),
+			},
+		},
+		"empty-scope": {
+			err: true,
+			opts: []TokenOpt{
+				WithScopes("repo:read", ""),
+			},
+		},
+		"invalid-issuer": {
+			err: true,
+			opts: []TokenOpt{
+				WithIssuer(""),
+			},
+		},
+		"invalid-audience": {
+			err: true,
+			opts: []TokenOpt{
+				WithAudience(),
+			},
+		},
+		"empty-audience": {
+			err: true,
+			opts: []TokenOpt{
+				WithAudience("admin", ""),
+			},
+		},
+		"invalid-subject": {
+			err: true,
+			opts: []TokenOpt{
+				WithSubject(""),
 			},
 		},
 		"invalid-id": {
@@ -75,10 +116,17 @@ func TestTokenOpts(t *testing.T) {
 
 func TestTokenOptsOverwrite(t *testing.T) {
 	testCases := map[string]TokenOpt{
-		"id":     WithID(t.Name()),
-		"sid":    WithSID(t.Name()),
-		"usages": WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
-		"vendor": WithVendor([]byte(t.Name())),
+		"id":       WithID(t.Name()),
+		"sid":      WithSID(t.Name()),
+		"usages":   WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN),
+		"vendor":   WithVendor([]byte(t.Name())),
+		"issuer":   WithIssuer(t.Name()),
+		"audience": WithAudience(t.Name()),
+		"subject":  WithSubject(t.Name()),
+		"scopes":   WithScopes(t.Name()),
+
+		"issued at":       WithTokenClock(SystemClock),
+		"validity window": WithValidityWindow(time.Now(), time.Now().Add(time.Hour)),
 	}
 
 	for n, tc := range testCases {
@@ -92,3 +140,65 @@ func TestTokenOptsOverwrite(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTokenClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tok, err := New(time.Hour, WithTokenClock(prototokenstest.NewFakeClock(start)))
+	require.NoError(t, err)
+	require.Equal(t, start, tok.GetTimestamps().GetNotValidBefore().AsTime())
+	require.Equal(t, start.Add(time.Hour), tok.GetTimestamps().GetNotValidAfter().AsTime())
+
+	require.Equal(t, start, tok.GetTimestamps().GetIssuedAt().AsTime())
+
+	_, err = New(time.Hour, WithTokenClock(nil))
+	require.Error(t, err)
+
+	// an explicit window is kept regardless of option order
+	nvb := start.Add(24 * time.Hour)
+	tok, err = New(time.Hour, WithNotValidBefore(nvb), WithTokenClock(prototokenstest.NewFakeClock(start)))
+	require.NoError(t, err)
+	require.Equal(t, start, tok.GetTimestamps().GetIssuedAt().AsTime())
+	require.Equal(t, nvb, tok.GetTimestamps().GetNotValidBefore().AsTime())
+	require.Equal(t, nvb.Add(time.Hour), tok.GetTimestamps().GetNotValidAfter().AsTime())
+
+	// a window equal to the clock's time is still explicit
+	tok, err = New(time.Hour, WithValidityWindow(start, start.Add(24*time.Hour)), WithTokenClock(prototokenstest.NewFakeClock(start)))
+	require.NoError(t, err)
+	require.Equal(t, start.Add(24*time.Hour), tok.GetTimestamps().GetNotValidAfter().AsTime(), "should keep the explicit window")
+}
+
+func TestValidityWindow(t *testing.T) {
+	before := time.Now().UTC()
+	nvb := before.Add(24 * time.Hour)
+	tok, err := New(time.Hour, WithNotValidBefore(nvb))
+	require.NoError(t, err)
+	require.Equal(t, nvb, tok.GetTimestamps().GetNotValidBefore().AsTime())
+	require.Equal(t, nvb.Add(time.Hour), tok.GetTimestamps().GetNotValidAfter().AsTime(), "should keep the duration")
+	require.False(t, IssuedAt(tok).Before(before), "issued at should still be now")
+	require.True(t, IssuedAt(tok).Before(nvb))
+
+	nva := nvb.Add(30 * 24 * time.Hour)
+	tok, err = New(time.Hour, WithValidityWindow(nvb, nva))
+	require.NoError(t, err)
+	require.Equal(t, nvb, tok.GetTimestamps().GetNotValidBefore().AsTime())
+	require.Equal(t, nva, tok.GetTimestamps().GetNotValidAfter().AsTime(), "should ignore the duration")
+
+	// the window can only be set once
+	_, err = New(time.Hour, WithNotValidBefore(nvb), WithValidityWindow(nvb, nva))
+	require.ErrorIs(t, err, ErrOverwrite)
+	_, err = New(time.Hour, WithValidityWindow(nvb, nva), WithNotValidBefore(nvb))
+	require.ErrorIs(t, err, ErrOverwrite)
+	_, err = New(time.Hour, WithNotValidBefore(nvb), WithNotValidBefore(nva))
+	require.ErrorIs(t, err, ErrOverwrite)
+
+	_, err = New(time.Hour, WithNotValidBefore(time.Time{}))
+	require.Error(t, err)
+	_, err = New(time.Hour, WithValidityWindow(nva, nvb))
+	require.Error(t, err)
+	_, err = New(time.Hour, WithValidityWindow(time.Time{}, nva))
+	require.Error(t, err)
+
+	// tokens from before issued_at existed use not valid before
+	legacy := &tokenpb.ProtoToken{Timestamps: &tokenpb.Timestamps{NotValidBefore: tok.GetTimestamps().GetNotValidBefore()}}
+	require.Equal(t, nvb, IssuedAt(legacy))
+}
@@ -0,0 +1,107 @@
+package prototokens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ValidationReason is a machine-readable reason a token failed validation
+type ValidationReason int
+
+const (
+	// ReasonUnknown is the zero value and should not be returned by implementations
+	ReasonUnknown ValidationReason = iota
+	// ReasonMalformed means the token could not be decoded or unmarshaled
+	ReasonMalformed
+	// ReasonInvalidSignature means the signature did not verify or the token was tampered with
+	ReasonInvalidSignature
+	// ReasonKeyData means the key material needed to verify the token was unavailable or invalid
+	// this is not the fault of the token
+	ReasonKeyData
+	// ReasonNotYetValid means the token's validity window hasn't started
+	ReasonNotYetValid
+	// ReasonExpired means the token's validity window has ended or it is older than the maximum age
+	ReasonExpired
+	// ReasonRevoked means the token has been revoked
+	ReasonRevoked
+	// ReasonRevocationCheck means revocation could not be checked. this is not the fault of the token
+	ReasonRevocationCheck
+	// ReasonUsage means the token is valid but not for the requested usage
+	ReasonUsage
+	// ReasonScope means the token is valid but its scopes don't satisfy the required scopes
+	ReasonScope
+	// ReasonClaim means the token's issuer, audience or subject doesn't match what the verifier requires
+	ReasonClaim
+	// ReasonRequirement means the token is valid but does not meet a [Requirement] such as [RequireSID]
+	ReasonRequirement
+)
+
+var reasonNames = map[ValidationReason]string{
+	ReasonUnknown:          "unknown",
+	ReasonMalformed:        "malformed",
+	ReasonInvalidSignature: "invalid_signature",
+	ReasonKeyData:          "key_data",
+	ReasonNotYetValid:      "not_yet_valid",
+	ReasonExpired:          "expired",
+	ReasonRevoked:          "revoked",
+	ReasonRevocationCheck:  "revocation_check",
+	ReasonUsage:            "usage",
+	ReasonScope:            "scope",
+	ReasonClaim:            "claim",
+	ReasonRequirement:      "requirement",
+}
+
+// String returns a stable snake_case name for the reason suitable for logs and metrics
+func (vr ValidationReason) String() string {
+	if name, ok := reasonNames[vr]; ok {
+		return name
+	}
+	return fmt.Sprintf("ValidationReason(%d)", int(vr))
+}
+
+// ValidationError is the error returned when a token fails validation
+// use [errors.As] to get at the details. [errors.Is] still works for the sentinel errors in the cause
+type ValidationError struct {
+	// Reason is the machine-readable reason for the failure
+	Reason ValidationReason
+	// ID is the token's id. it is only set once the token's signature has been verified
+	ID string
+	// SID is the token's sid. it is only set once the token's signature has been verified
+	SID string
+	// Delta is how far before NotValidBefore or after NotValidAfter (or the maximum age) the token was for [ReasonNotYetValid] and [ReasonExpired]
+	Delta time.Duration
+	// Err is the underlying cause
+	Err error
+}
+
+// NewValidationError returns a new [ValidationError] for the provided reason and cause
+// implementations should add ID and SID only after verifying the token
+func NewValidationError(reason ValidationReason, cause error) *ValidationError {
+	return &ValidationError{Reason: reason, Err: cause}
+}
+
+// SignatureError returns a [ValidationError] for a signature that could not be verified
+// errors loading key material are reported as [ReasonKeyData] rather than [ReasonInvalidSignature]
+// implementations must only wrap [ErrKeyData] for failures of their own key material and never for anything the token controls
+// such as an unknown key id so a forged token can't pass itself off as a server-side failure
+func SignatureError(err error) *ValidationError {
+	reason := ReasonInvalidSignature
+	if errors.Is(err, ErrKeyData) {
+		reason = ReasonKeyData
+	}
+	return NewValidationError(reason, fmt.Errorf("%w: %w", ErrInvalidSignature, err))
+}
+
+// Error returns the message of the underlying cause
+func (ve *ValidationError) Error() string {
+	if ve.Err == nil {
+		return fmt.Sprintf("token failed validation: %s", ve.Reason)
+	}
+	return ve.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+func (ve *ValidationError) Unwrap() error {
+	return ve.Err
+}
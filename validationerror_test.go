@@ -0,0 +1,76 @@
+package prototokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+	"github.com/lusis/prototokens/prototokenstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationError(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := prototokenstest.NewFakeClock(start)
+	mc, err := NewManagerConfig(WithClock(fc))
+	require.NoError(t, err)
+	tok, err := New(time.Hour, WithTokenClock(fc), WithID(t.Name()), WithSID(t.Name()+"_sid"), WithUsages(tokenpb.TokenUsages_TOKEN_USAGES_HUMAN))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		now    time.Time
+		reason ValidationReason
+		delta  time.Duration
+		is     error
+	}{
+		"not-yet-valid": {now: start.Add(-time.Minute), reason: ReasonNotYetValid, delta: time.Minute, is: ErrNotYetValid},
+		"expired":       {now: start.Add(2 * time.Hour), reason: ReasonExpired, delta: time.Hour, is: ErrNoLongerValid},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			fc.Set(tc.now)
+			err := mc.ValidateToken(ctx, tok)
+			require.ErrorIs(t, err, tc.is, "should still match the sentinel")
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			require.Equal(t, tc.reason, ve.Reason)
+			require.Equal(t, tc.delta, ve.Delta)
+			require.Equal(t, tok.GetId(), ve.ID)
+			require.Equal(t, tok.GetSid(), ve.SID)
+		})
+	}
+	t.Run("usage", func(t *testing.T) {
+		err := CheckUsage(tok, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE)
+		require.ErrorIs(t, err, ErrNotValidForUsage)
+		var ve *ValidationError
+		require.ErrorAs(t, fmt.Errorf("%w: %w", ErrNotValid, err), &ve, "should be found through wrapping")
+		require.Equal(t, ReasonUsage, ve.Reason)
+		require.Equal(t, tok.GetId(), ve.ID)
+	})
+	t.Run("signature", func(t *testing.T) {
+		ve := SignatureError(ErrTamper)
+		require.Equal(t, ReasonInvalidSignature, ve.Reason)
+		require.ErrorIs(t, ve, ErrInvalidSignature)
+		require.ErrorIs(t, ve, ErrTamper)
+		require.Empty(t, ve.ID, "unverified tokens should not report an id")
+		require.Equal(t, ReasonKeyData, SignatureError(ErrKeyData).Reason)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, err := mc.Decode("!!!")
+		require.ErrorIs(t, err, ErrDecode)
+		var ve *ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, ReasonMalformed, ve.Reason)
+	})
+	t.Run("strings", func(t *testing.T) {
+		require.Equal(t, "expired", ReasonExpired.String())
+		require.Equal(t, "ValidationReason(99)", ValidationReason(99).String())
+		require.Equal(t, "token failed validation: revoked", (&ValidationError{Reason: ReasonRevoked}).Error())
+		require.Nil(t, errors.Unwrap(&ValidationError{}))
+	})
+}
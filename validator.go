@@ -0,0 +1,146 @@
+package prototokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lusis/prototokens/internal"
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// OpenFunc authenticates a [tokenpb.SignedToken] and returns the marshaled [tokenpb.ProtoToken] it carries
+// it must never return bytes that haven't been verified or decrypted
+// errors are reported via [SignatureError] unless they are already a [ValidationError]
+type OpenFunc func(context.Context, *tokenpb.SignedToken) ([]byte, error)
+
+// Validator implements the validation methods of [TokenManager] on top of an [OpenFunc]
+// so implementations only differ in how a token is signed and authenticated
+// implementations embed it and provide Sign, Encode, Decode and RevokeToken themselves
+type Validator struct {
+	*UnimplementedTokenManager
+	cfg  *ManagerConfig
+	open OpenFunc
+}
+
+// NewValidator returns a new [Validator] that authenticates tokens with open and validates them with cfg
+func NewValidator(cfg *ManagerConfig, open OpenFunc) *Validator {
+	return &Validator{cfg: cfg, open: open}
+}
+
+// GetValidatedToken turns a [tokenpb.SignedToken] into a [tokenpb.ProtoToken] after validation
+func (v *Validator) GetValidatedToken(ctx context.Context, st *tokenpb.SignedToken) (*tokenpb.ProtoToken, error) {
+	ctx, span := internal.StartSpan(ctx, "GetValidatedToken")
+	defer span.End()
+	pt, err := v.validate(ctx, st, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return pt, nil
+}
+
+// GetValidatedTokenFor turns a [tokenpb.SignedToken] into a [tokenpb.ProtoToken] after validating it for the provided usage
+// this is the only way to get at a token restricted to [tokenpb.TokenUsages_TOKEN_USAGES_REVOCATION_LIST]
+func (v *Validator) GetValidatedTokenFor(ctx context.Context, st *tokenpb.SignedToken, usage tokenpb.TokenUsages) (*tokenpb.ProtoToken, error) {
+	ctx, span := internal.StartSpan(ctx, "GetValidatedTokenFor")
+	defer span.End()
+	pt, err := v.validate(ctx, st, []tokenpb.TokenUsages{usage}, RequireUsages(usage))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return pt, nil
+}
+
+// ValidFor checks if a token is valid for a specific usage
+func (v *Validator) ValidFor(ctx context.Context, st *tokenpb.SignedToken, usage tokenpb.TokenUsages) error {
+	ctx, span := internal.StartSpan(ctx, "ValidFor")
+	defer span.End()
+	if _, err := v.validate(ctx, st, []tokenpb.TokenUsages{usage}, RequireUsages(usage)); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return nil
+}
+
+// ValidForAll checks if a token is valid for every provided usage with a single verification
+func (v *Validator) ValidForAll(ctx context.Context, st *tokenpb.SignedToken, usages ...tokenpb.TokenUsages) error {
+	ctx, span := internal.StartSpan(ctx, "ValidForAll")
+	defer span.End()
+	if _, err := v.validate(ctx, st, usages, RequireUsages(usages...)); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return nil
+}
+
+// ValidForAny checks if a token is valid for at least one of the provided usages with a single verification
+func (v *Validator) ValidForAny(ctx context.Context, st *tokenpb.SignedToken, usages ...tokenpb.TokenUsages) error {
+	ctx, span := internal.StartSpan(ctx, "ValidForAny")
+	defer span.End()
+	if _, err := v.validate(ctx, st, usages, RequireAnyUsage(usages...)); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return nil
+}
+
+// ValidForScopes checks if a token's scopes satisfy every provided scope
+func (v *Validator) ValidForScopes(ctx context.Context, st *tokenpb.SignedToken, scopes ...string) error {
+	ctx, span := internal.StartSpan(ctx, "ValidForScopes")
+	defer span.End()
+	if _, err := v.validate(ctx, st, nil, RequireScopes(scopes...)); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return nil
+}
+
+// ValidateAndParse decodes, authenticates and validates an encoded token against the requirements in a single pass
+func (v *Validator) ValidateAndParse(ctx context.Context, encoded string, reqs ...Requirement) (*tokenpb.ProtoToken, error) {
+	ctx, span := internal.StartSpan(ctx, "ValidateAndParse")
+	defer span.End()
+	st, err := v.cfg.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := v.validate(ctx, st, nil, reqs...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotValid, err)
+	}
+	return pt, nil
+}
+
+// Validate checks if the token is valid
+// we do the validation in layers based on how expensive it is to validate
+// - authenticate the raw bytes with the [OpenFunc] so unauthenticated input never reaches the protobuf parser
+// - unmarshal the token bytes once now that we know we can trust them
+// - check timestamps and revocation via [ManagerConfig.ValidateToken]
+func (v *Validator) Validate(ctx context.Context, st *tokenpb.SignedToken) error {
+	ctx, span := internal.StartSpan(ctx, "Validate")
+	defer span.End()
+	_, err := v.validate(ctx, st, nil)
+	return err
+}
+
+// validate does the work of every validation method returning the parsed token
+// the token is authenticated, unmarshaled and checked exactly once
+// requested are the usages the caller explicitly asked for which are passed to [ManagerConfig.ValidateToken]
+func (v *Validator) validate(ctx context.Context, st *tokenpb.SignedToken, requested []tokenpb.TokenUsages, reqs ...Requirement) (*tokenpb.ProtoToken, error) {
+	b, err := v.open(ctx, st)
+	if err != nil {
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			return nil, err
+		}
+		return nil, SignatureError(err)
+	}
+	tok := &tokenpb.ProtoToken{}
+	if err := proto.Unmarshal(b, tok); err != nil {
+		return nil, NewValidationError(ReasonMalformed, fmt.Errorf("%w: %w", ErrUnmarshal, err))
+	}
+	if err := v.cfg.ValidateToken(ctx, tok, requested...); err != nil {
+		return nil, err
+	}
+	if err := CheckRequirements(tok, reqs...); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,92 @@
+package prototokens
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testOpen accepts any token whose signature is "ok"
+func testOpen(_ context.Context, st *tokenpb.SignedToken) ([]byte, error) {
+	if !bytes.Equal(st.GetSignature(), []byte("ok")) {
+		return nil, ErrTamper
+	}
+	return st.GetPrototoken(), nil
+}
+
+func TestValidator(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := NewManagerConfig()
+	require.NoError(t, err)
+	v := NewValidator(cfg, testOpen)
+	require.Implements(t, (*TokenManager)(nil), v)
+
+	human, machine := tokenpb.TokenUsages_TOKEN_USAGES_HUMAN, tokenpb.TokenUsages_TOKEN_USAGES_MACHINE
+	pt, err := New(time.Hour, WithUsages(human), WithScopes("repo:*"))
+	require.NoError(t, err)
+	b, err := proto.Marshal(pt)
+	require.NoError(t, err)
+	st := &tokenpb.SignedToken{Prototoken: b, Signature: []byte("ok")}
+	encoded, err := cfg.Encode(st)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Validate(ctx, st))
+	vt, err := v.GetValidatedToken(ctx, st)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(pt, vt))
+	vt, err = v.ValidateAndParse(ctx, encoded, RequireUsages(human))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(pt, vt))
+	vt, err = v.GetValidatedTokenFor(ctx, st, human)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(pt, vt))
+	_, err = v.GetValidatedTokenFor(ctx, st, machine)
+	require.ErrorIs(t, err, ErrNotValidForUsage)
+	require.NoError(t, v.ValidFor(ctx, st, human))
+	require.NoError(t, v.ValidForAll(ctx, st, human))
+	require.NoError(t, v.ValidForAny(ctx, st, machine, human))
+	require.NoError(t, v.ValidForScopes(ctx, st, "repo:read"))
+
+	require.ErrorIs(t, v.ValidFor(ctx, st, machine), ErrNotValidForUsage)
+	require.ErrorIs(t, v.ValidForAll(ctx, st, human, machine), ErrNotValidForUsage)
+	require.ErrorIs(t, v.ValidForAny(ctx, st, machine), ErrNotValidForUsage)
+	require.ErrorIs(t, v.ValidForAll(ctx, st), ErrNotValidForUsage, "no usages should fail closed")
+	require.ErrorIs(t, v.ValidForScopes(ctx, st, "org:read"), ErrNotValidForScopes)
+	require.ErrorIs(t, v.ValidForScopes(ctx, st), ErrNotValidForScopes, "no scopes should fail closed")
+	_, err = v.ValidateAndParse(ctx, encoded, RequireSID("other"))
+	require.ErrorIs(t, err, ErrNotValid)
+	require.ErrorIs(t, err, ErrRequirement)
+
+	t.Run("open-error", func(t *testing.T) {
+		forged := &tokenpb.SignedToken{Prototoken: b, Signature: []byte("forged")}
+		err := v.Validate(ctx, forged)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		require.ErrorIs(t, err, ErrTamper)
+		var ve *ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, ReasonInvalidSignature, ve.Reason)
+		require.Empty(t, ve.ID, "unauthenticated tokens should not leak an id")
+	})
+	t.Run("validation-error", func(t *testing.T) {
+		malformed := NewValidationError(ReasonMalformed, ErrDecode)
+		v := NewValidator(cfg, func(context.Context, *tokenpb.SignedToken) ([]byte, error) { return nil, malformed })
+		var ve *ValidationError
+		require.ErrorAs(t, v.Validate(ctx, st), &ve)
+		require.Equal(t, ReasonMalformed, ve.Reason, "validation errors from open should be kept as is")
+	})
+	t.Run("unmarshal", func(t *testing.T) {
+		garbage := &tokenpb.SignedToken{Prototoken: []byte{0xff, 0xff}, Signature: []byte("ok")}
+		err := v.Validate(ctx, garbage)
+		require.ErrorIs(t, err, ErrUnmarshal)
+		var ve *ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, ReasonMalformed, ve.Reason)
+	})
+}
@@ -0,0 +1,35 @@
+package prototokens
+
+import (
+	"fmt"
+
+	tokenpb "github.com/lusis/prototokens/proto/gen/go/prototokens/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// VendorAs returns the vendor message stored with [WithVendorMessage] as T
+// only call it on a token returned by validation since the vendor message is as trustworthy as the token
+// - returns [ErrNoVendorMessage] if the token has no vendor message
+// - returns [ErrVendorMessageType] naming both types if the message is not a T
+//
+//	claims, err := prototokens.VendorAs[*mypb.Claims](token)
+func VendorAs[T proto.Message](tok *tokenpb.ProtoToken) (T, error) {
+	var zero T
+	if any(zero) == nil {
+		// T is an interface such as proto.Message so there is nothing to unmarshal into
+		return zero, fmt.Errorf("%w: requested type must be a concrete message type", ErrVendorMessageType)
+	}
+	a := tok.GetVendorMessage()
+	if a == nil {
+		return zero, ErrNoVendorMessage
+	}
+	msg := zero.ProtoReflect().New().Interface()
+	if !a.MessageIs(msg) {
+		return zero, fmt.Errorf("%w: want %s have %s", ErrVendorMessageType, msg.ProtoReflect().Descriptor().FullName(), a.MessageName())
+	}
+	if err := a.UnmarshalTo(msg); err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	}
+	return msg.(T), nil
+}
@@ -0,0 +1,50 @@
+package prototokens
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorMessage(t *testing.T) {
+	claims, err := structpb.NewStruct(map[string]interface{}{"plan": "enterprise", "seats": 25})
+	require.NoError(t, err)
+	tok, err := New(time.Hour, WithVendorMessage(claims))
+	require.NoError(t, err)
+	require.Nil(t, tok.GetVendor(), "raw vendor bytes should not be set")
+
+	// survive a round trip through the wire
+	b, err := proto.Marshal(tok)
+	require.NoError(t, err)
+	tok.Reset()
+	require.NoError(t, proto.Unmarshal(b, tok))
+
+	got, err := VendorAs[*structpb.Struct](tok)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(claims, got), "vendor message should round trip")
+
+	_, err = VendorAs[*wrapperspb.StringValue](tok)
+	require.ErrorIs(t, err, ErrVendorMessageType)
+	require.ErrorContains(t, err, "want google.protobuf.StringValue have google.protobuf.Struct")
+
+	_, err = VendorAs[proto.Message](tok)
+	require.ErrorIs(t, err, ErrVendorMessageType)
+
+	plain, err := New(time.Hour, WithVendor([]byte("raw")))
+	require.NoError(t, err)
+	_, err = VendorAs[*structpb.Struct](plain)
+	require.ErrorIs(t, err, ErrNoVendorMessage)
+
+	_, err = New(time.Hour, WithVendorMessage(nil))
+	require.Error(t, err)
+	_, err = New(time.Hour, WithVendor([]byte("raw")), WithVendorMessage(durationpb.New(time.Second)))
+	require.ErrorIs(t, err, ErrOverwrite, "a token carries either vendor bytes or a vendor message")
+	_, err = New(time.Hour, WithVendorMessage(durationpb.New(time.Second)), WithVendor([]byte("raw")))
+	require.ErrorIs(t, err, ErrOverwrite)
+}